@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+type AccessLogConfig struct {
+	Enabled bool   `toml:"enabled" comment:"Log every HTTP request, including 404s, separately from the application log"`
+	Format  string `toml:"format" comment:"combined (the Apache combined log format, followed by the duration in seconds\nand the request ID) or json"`
+	Path    string `toml:"path" comment:"Append to this file; written to stdout if unset"`
+}
+
+// accessWriter records the status and size of a response
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (a *accessWriter) WriteHeader(status int) {
+	if a.status == 0 {
+		a.status = status
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+func (a *accessWriter) Write(p []byte) (int, error) {
+	if a.status == 0 {
+		a.status = http.StatusOK
+	}
+	n, err := a.ResponseWriter.Write(p)
+	a.bytes += int64(n)
+	return n, err
+}
+
+func (a *accessWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(a.ResponseWriter).Hijack()
+	if err == nil && a.status == 0 {
+		a.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (a *accessWriter) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
+type accessEntry struct {
+	time      time.Time
+	method    string
+	path      string
+	proto     string
+	status    int
+	bytes     int64
+	duration  time.Duration
+	ip        string
+	referer   string
+	userAgent string
+	requestID string
+}
+
+// accessLogger writes one line per request, in the combined log format
+// (the default) or as JSON
+type accessLogger struct {
+	mu   sync.Mutex
+	out  io.Writer
+	json *slog.Logger
+}
+
+func newAccessLogger(config AccessLogConfig) (*accessLogger, error) {
+	var out io.Writer = os.Stdout
+	if config.Path != "" {
+		f, err := os.OpenFile(config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		out = f
+	}
+
+	l := &accessLogger{out: out}
+	switch config.Format {
+	case "", "combined":
+	case "json":
+		l.json = slog.New(slog.NewJSONHandler(out, nil))
+	default:
+		return nil, fmt.Errorf("invalid access log format %q", config.Format)
+	}
+	return l, nil
+}
+
+func (l *accessLogger) log(e accessEntry) {
+	if l.json != nil {
+		l.json.Info("access",
+			"method", e.method,
+			"path", e.path,
+			"status", e.status,
+			"bytes", e.bytes,
+			"duration", e.duration.Seconds(),
+			"ip", e.ip,
+			"user_agent", e.userAgent,
+			"request_id", e.requestID,
+		)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, "%s - - [%s] %q %d %d %q %q %.3f %s\n",
+		e.ip, e.time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.method+" "+e.path+" "+e.proto, e.status, e.bytes,
+		orDash(e.referer), orDash(e.userAgent), e.duration.Seconds(), orDash(e.requestID))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// accessLog logs every request handled by h, including those the mux
+// rejects
+func accessLog(h http.Handler, config AccessLogConfig) (http.Handler, error) {
+	if !config.Enabled {
+		return h, nil
+	}
+
+	l, err := newAccessLogger(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		aw := &accessWriter{ResponseWriter: w}
+		h.ServeHTTP(aw, r)
+
+		if aw.status == 0 {
+			aw.status = http.StatusOK
+		}
+		l.log(accessEntry{
+			time:      start,
+			method:    r.Method,
+			path:      r.URL.RequestURI(),
+			proto:     r.Proto,
+			status:    aw.status,
+			bytes:     aw.bytes,
+			duration:  time.Since(start),
+			ip:        clientHost(getIP(r)),
+			referer:   r.Referer(),
+			userAgent: r.UserAgent(),
+			requestID: requestIDFrom(r.Context()),
+		})
+	}), nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// adminToken is a bearer token granting every admin permission. The admin
+// endpoints are disabled if it is empty and OpenID Connect isn't configured
+var adminToken string
+
+type DictionaryStatus struct {
+	Words    int       `json:"words"`
+	Answers  int       `json:"answers"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+type WorkersRequest struct {
+	Engine  string `json:"engine,omitempty"`
+	Pool    string `json:"pool,omitempty"`
+	Workers int    `json:"workers"`
+}
+
+// authorizeAdmin checks the request carries an operator certificate, the
+// admin token or the session of an admin granted perm, and returns who made it
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, perm string) (string, bool) {
+	// Only the debug listener asks for client certificates, and only
+	// operators are issued them
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+	}
+	if adminToken == "" && adminOIDC == nil {
+		httpError(w, "Admin API disabled", http.StatusForbidden)
+		return "", false
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && adminToken != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			return "token", true
+		}
+	} else if adminOIDC != nil {
+		if session, ok := adminOIDC.session(r); ok {
+			if !slices.Contains(session.Permissions, perm) {
+				httpError(w, "Not permitted to manage "+perm, http.StatusForbidden)
+				slog.WarnContext(r.Context(), "Forbidden admin request", "ip", getIP(r), "sub", session.Subject, "permission", perm)
+				return "", false
+			}
+			return session.Subject, true
+		}
+	}
+
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	httpError(w, "Invalid admin token or session", http.StatusUnauthorized)
+	slog.WarnContext(r.Context(), "Unauthorized admin request", "ip", getIP(r))
+	return "", false
+}
+
+func resizeWorkers(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+	admin, ok := authorizeAdmin(w, r, permWorkers)
+	if !ok {
+		return
+	}
+
+	var req WorkersRequest
+	if err := decodeBody(r, &req); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	botsMu.Lock()
+	var targets []*Bot
+	for name, b := range bots {
+		if req.Engine == "" || req.Engine == name {
+			targets = append(targets, b)
+		}
+	}
+	botsMu.Unlock()
+
+	if len(targets) == 0 {
+		httpError(w, "Unknown engine", http.StatusNotFound)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin request to resize workers", "ip", getIP(r), "admin", admin, "engine", req.Engine, "pool", req.Pool, "workers", req.Workers)
+	for _, b := range targets {
+		if err := b.resize(req.Pool, req.Workers); err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, r, queueStatuses())
+}
+
+// reloadDictionary reloads the word lists from the engine or wordlist_path
+func reloadDictionary(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+	admin, ok := authorizeAdmin(w, r, permDictionary)
+	if !ok {
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin request to reload dictionary", "ip", getIP(r), "admin", admin)
+	d, err := refreshDictionary()
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	writeJSON(w, r, DictionaryStatus{Words: len(d.Words), Answers: len(d.Answers), LoadedAt: d.LoadedAt})
+}
+
+// engineConfigs lists config and every engine configured within it
+func engineConfigs(config BotConfig) []BotConfig {
+	configs := []BotConfig{config}
+	for i := range config.Backends {
+		configs = append(configs, engineConfigs(config.backendConfig(i))...)
+	}
+	if config.Shadow != nil {
+		configs = append(configs, engineConfigs(config.shadowConfig())...)
+	}
+	if config.Canary != nil {
+		configs = append(configs, engineConfigs(config.canaryConfig())...)
+	}
+	return configs
+}
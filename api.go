@@ -0,0 +1,229 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const apiVersion = "1"
+
+type apiParam struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type apiOperation struct {
+	Method   string
+	Summary  string
+	Params   []apiParam
+	Body     any
+	Response any
+}
+
+type apiRoute struct {
+	Path       string
+	Handler    http.Handler
+	Operations []apiOperation
+}
+
+var wordParam = apiParam{"w", "string", true, "Target word"}
+
+// dictParam chooses a dictionary from [dictionaries] and lenParam a word
+// length it supports; every endpoint but the admin ones and
+// defaultDictionaryRoutes takes them
+var dictParam = apiParam{"dict", "string", false, "Dictionary to use instead of the engine's own"}
+
+var lenParam = apiParam{"len", "integer", false, "Number of letters, one of those the dictionary supports"}
+
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{"/solve", http.HandlerFunc(solveWord), []apiOperation{
+			{"GET", "Reports for each turn of the engine solving w", []apiParam{
+				wordParam,
+				{"format", "string", false, "ndjson, msgpack or protobuf"},
+			}, nil, []WordReport{}},
+			{"POST", "Reports for each turn of the engine solving word", nil, SolveRequest{}, []WordReport{}},
+		}},
+		{"/coach", http.HandlerFunc(coachWord), []apiOperation{
+			{"GET", "Report on the last guess", []apiParam{
+				{"w", "string", false, "Target word; if omitted, guesses carry their colors"},
+				{"guess", "string", true, "Comma-separated guesses, like slate,brine or crane:bygbb,slate:ggbbb"},
+				{"format", "string", false, "msgpack or protobuf"},
+			}, nil, &WordReport{}},
+			{"POST", "Report on the last guess", nil, CoachRequest{}, &WordReport{}},
+		}},
+		{"/suggest", http.HandlerFunc(suggestWords), []apiOperation{
+			{"GET", "Ranked guesses given known letters", []apiParam{
+				{"green", "string", false, "Known letters, with _ for unknown positions"},
+				{"yellow", "string", false, "Letters with a 1-based position they are not at, like r:1,t:3"},
+				{"gray", "string", false, "Letters not in the word"},
+				{"format", "string", false, "msgpack or protobuf"},
+			}, nil, &Suggestion{}},
+		}},
+		{"/ws/coach", http.HandlerFunc(coachSocket), []apiOperation{
+			{"GET", "WebSocket coaching session", []apiParam{
+				{"w", "string", false, "Target word"},
+			}, nil, nil},
+		}},
+		{"/simulate", http.HandlerFunc(simulateWord), []apiOperation{
+			{"GET", "Transcript of the engine playing against w", []apiParam{wordParam}, nil, Transcript{}},
+		}},
+		{"/score", conditional(http.HandlerFunc(scoreWord)), []apiOperation{
+			{"GET", "Colors for a guess against a target", []apiParam{
+				{"t", "string", true, "Target word"},
+				{"g", "string", true, "Guess"},
+			}, nil, ScoreResult{}},
+		}},
+		{"/validate", conditional(http.HandlerFunc(validateWord)), []apiOperation{
+			{"GET", "Whether w is in the guess and answer lists", []apiParam{wordParam}, nil, Validation{}},
+		}},
+		{"/words", conditional(http.HandlerFunc(listWords)), []apiOperation{
+			{"GET", "A page of the dictionary", []apiParam{
+				{"offset", "integer", false, ""},
+				{"limit", "integer", false, ""},
+				{"prefix", "string", false, ""},
+				{"contains", "string", false, ""},
+				{"list", "string", false, "all or answers"},
+			}, nil, WordPage{}},
+		}},
+		{"/openers", conditional(http.HandlerFunc(listOpeners)), []apiOperation{
+			{"GET", "The best opening guesses", []apiParam{
+				{"n", "integer", false, "Number of guesses"},
+			}, nil, []Guess{}},
+		}},
+		{"/daily", http.HandlerFunc(playDaily), []apiOperation{
+			{"GET", "Colors for a guess against today's word", []apiParam{
+				{"g", "string", false, "Guess"},
+			}, nil, DailyResult{}},
+		}},
+		{"/game/new", http.HandlerFunc(newGame), []apiOperation{
+			{"POST", "Start a game", []apiParam{
+				{"hard", "boolean", false, "Enforce hard mode"},
+			}, nil, Game{}},
+		}},
+		{"/game/{id}", http.HandlerFunc(getGame), []apiOperation{
+			{"GET", "The current state of a game", []apiParam{
+				{"id", "string", true, ""},
+			}, nil, Game{}},
+		}},
+		{"/game/{id}/guess", http.HandlerFunc(guessGame), []apiOperation{
+			{"POST", "Submit a guess", []apiParam{
+				{"id", "string", true, ""},
+				{"g", "string", true, "Guess"},
+			}, nil, Game{}},
+		}},
+		{"/jobs/solve", http.HandlerFunc(submitSolveJob), []apiOperation{
+			{"POST", "Start solving word in the background", nil, SolveRequest{}, Job{}},
+		}},
+		{"/jobs/{id}", http.HandlerFunc(getJob), []apiOperation{
+			{"GET", "The state of a job, and its reports once done", []apiParam{
+				{"id", "string", true, ""},
+			}, nil, Job{}},
+		}},
+		{"/graphql", newGraphqlHandler(), []apiOperation{
+			{"POST", "GraphQL query", nil, graphqlRequest{}, map[string]any{}},
+		}},
+		{"/admin/workers", http.HandlerFunc(resizeWorkers), []apiOperation{
+			{"POST", "Resize engine workers; requires the admin token as a bearer token, or an admin session with the workers permission", nil, WorkersRequest{}, map[string]QueueStatus{}},
+		}},
+		{"/admin/dictionary", http.HandlerFunc(reloadDictionary), []apiOperation{
+			{"POST", "Reload the word lists from the engine; requires the admin token as a bearer token, or an admin session with the dictionary permission", nil, nil, DictionaryStatus{}},
+		}},
+		{"/admin/config", http.HandlerFunc(serveReloadConfig), []apiOperation{
+			{"POST", "Reload the server config, applying what can be changed without a restart; requires the admin token as a bearer token, or an admin session with the config permission", nil, nil, ReloadStatus{}},
+		}},
+		{"/admin/login", http.HandlerFunc(adminLogin), []apiOperation{
+			{"GET", "Log in as an admin with OpenID Connect", nil, nil, nil},
+		}},
+		{"/admin/callback", http.HandlerFunc(adminCallback), []apiOperation{
+			{"GET", "Where the identity provider redirects to after logging in, starting an admin session", []apiParam{
+				{"code", "string", true, ""},
+				{"state", "string", true, ""},
+			}, nil, AdminSession{}},
+		}},
+		{"/usage", http.HandlerFunc(serveUsage), []apiOperation{
+			{"GET", "Rate limit and daily quota remaining for the API key or JWT subject making the request", nil, nil, Usage{}},
+		}},
+		{"/status", http.HandlerFunc(serveStatus), []apiOperation{
+			{"GET", "Engine error rates and circuit breaker state", nil, nil, ServerStatus{}},
+		}},
+		{"/openapi.json", conditional(http.HandlerFunc(serveOpenapi)), []apiOperation{
+			{"GET", "This specification", nil, nil, map[string]any{}},
+		}},
+	}
+}
+
+func versioned(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Wbot-Api-Version", apiVersion)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// The unversioned paths predate /v1 and are kept for existing clients
+func deprecated(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Link", "</v"+apiVersion+r.URL.Path+`>; rel="successor-version"`)
+		h.ServeHTTP(w, r)
+	})
+}
+
+func checkRouteConfig(what string, config map[string]string, routes []apiRoute) {
+	for path := range config {
+		known := false
+		for _, route := range routes {
+			known = known || route.Path == path
+		}
+		if !known {
+			slog.Warn("Ignoring config for unknown route", "config", what, "route", path)
+		}
+	}
+}
+
+func checkRoutes(config ServerConfig) {
+	routes := apiRoutes()
+	checkRouteConfig("Cache-Control policy", config.CacheControl, routes)
+	checkRouteConfig("priority", config.Priority, routes)
+}
+
+// registerRoutes registers the API on mux, except for the admin endpoints,
+// which go on adminMux. Either may be nil to leave those endpoints out. The
+// API requires authentication with auth, unless it's nil
+func registerRoutes(mux, adminMux *http.ServeMux, config ServerConfig, auth *authenticator) {
+	for _, route := range apiRoutes() {
+		h := versioned(withRoutePriority(route.Handler, routePriority(config.Priority, route.Path)))
+		// The admin endpoints have a token and sessions of their own, and
+		// checking usage doesn't count against it
+		if !strings.HasPrefix(route.Path, "/admin/") {
+			if slices.Contains(defaultDictionaryRoutes, route.Path) {
+				h = noDictionary(h)
+			} else {
+				h = chooseDictionary(chooseLength(h))
+			}
+			if route.Path != "/usage" {
+				h = keyQuotas.limit(h)
+			}
+			if auth != nil {
+				h = auth.require(h)
+			}
+		}
+		h = traced(route.Path, instrument(route.Path, h))
+		if value, ok := config.CacheControl[route.Path]; ok {
+			h = withCacheControl(h, value)
+		}
+
+		m := mux
+		if strings.HasPrefix(route.Path, "/admin/") {
+			m = adminMux
+		}
+		if m != nil {
+			m.Handle("/v"+apiVersion+route.Path, h)
+			m.Handle(route.Path, deprecated(h))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var keyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "wbot_api_key_requests_total",
+	Help: "Authenticated API requests by key name.",
+}, []string{"key"})
+
+// apiKeys maps the SHA-256 of each key to its name, so looking a key up
+// doesn't leak how much of it matched
+type apiKeys map[[sha256.Size]byte]string
+
+func loadAPIKeys(config AuthConfig) (apiKeys, error) {
+	keys := apiKeys{}
+	for name, key := range config.APIKeys {
+		keys[sha256.Sum256([]byte(key))] = name
+	}
+	if config.KeyFile == "" {
+		return keys, nil
+	}
+
+	// Each line of the key file is a name and a key, separated by whitespace
+	f, err := os.Open(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a name and a key", config.KeyFile, line)
+		}
+		keys[sha256.Sum256([]byte(fields[1]))] = fields[0]
+	}
+	return keys, scanner.Err()
+}
+
+func (keys apiKeys) lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	name, ok := keys[sha256.Sum256([]byte(key))]
+	return name, ok
+}
+
+type apiKeyKey struct{}
+
+func withAPIKey(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, name)
+}
+
+// apiKeyFrom is the name of the key the request was made with
+func apiKeyFrom(ctx context.Context) string {
+	name, _ := ctx.Value(apiKeyKey{}).(string)
+	return name
+}
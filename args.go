@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Everything user supplied that ends up in an engine's argv, or on the stdin
+// of a coaching session, goes through these first. Words are lowercased by
+// the rules of words.language, normalized to NFC and must be letters, as many
+// as a dictionary allows, so none can be mistaken for a flag or break a line
+// of stdin, and positional words follow a "--" all the same. Other than that
+// they are passed on untouched, whatever their script
+
+func normalizeWord(word string) (string, error) {
+	word = foldWord(word)
+	if !wordValid(word) {
+		return "", InvalidArgsError{msg: fmt.Sprintf("invalid word %q", word)}
+	}
+	return word, nil
+}
+
+func normalizeWords(words []string) ([]string, error) {
+	normalized := make([]string, len(words))
+	for i, w := range words {
+		var err error
+		if normalized[i], err = normalizeWord(w); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
+
+func normalizeFeedback(feedback []Feedback) ([]string, error) {
+	args := make([]string, len(feedback))
+	for i, f := range feedback {
+		word, err := normalizeWord(f.Word)
+		if err != nil {
+			return nil, err
+		}
+		colors := strings.ToLower(f.Colors)
+		if !colorsValid(word, colors) {
+			return nil, InvalidArgsError{msg: fmt.Sprintf("invalid colors %q for %s", f.Colors, word)}
+		}
+		args[i] = Feedback{Word: word, Colors: colors}.String()
+	}
+	return args, nil
+}
+
+// lettersValid reports whether s consists of letters of wordScripts and any
+// of the runes in extra
+func lettersValid(s, extra string) bool {
+	for _, r := range s {
+		if !letterValid(r) && !strings.ContainsRune(extra, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func solveArgs(target string) ([]string, error) {
+	target, err := normalizeWord(target)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"solve", "-t", target}, nil
+}
+
+// solveTarget is the target of args built by solveArgs
+func solveTarget(args []string) (string, bool) {
+	if len(args) != 3 || args[0] != "solve" || args[1] != "-t" {
+		return "", false
+	}
+	return args[2], true
+}
+
+func coachArgs(target string, guesses []string) ([]string, error) {
+	target, err := normalizeWord(target)
+	if err != nil {
+		return nil, err
+	}
+	if guesses, err = normalizeWords(guesses); err != nil {
+		return nil, err
+	}
+	return append([]string{"coach", "-t", target, "--"}, guesses...), nil
+}
+
+func coachFeedbackArgs(feedback []Feedback) ([]string, error) {
+	guesses, err := normalizeFeedback(feedback)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{"coach", "--"}, guesses...), nil
+}
+
+func rankArgs(target string, shard, shards int, guesses []string) ([]string, error) {
+	target, err := normalizeWord(target)
+	if err != nil {
+		return nil, err
+	}
+	if guesses, err = normalizeWords(guesses); err != nil {
+		return nil, err
+	}
+	args := []string{"rank", "-t", target, "--shard", fmt.Sprintf("%d/%d", shard, shards), "--"}
+	return append(args, guesses...), nil
+}
+
+func suggestArgs(c Constraints) ([]string, error) {
+	green := foldWord(c.Green)
+	gray := foldWord(c.Gray)
+	n := utf8.RuneCountInString(green)
+	if !validLengths[n] || !lettersValid(green, "_") {
+		return nil, InvalidArgsError{msg: fmt.Sprintf("invalid green pattern %q", c.Green)}
+	}
+	if !lettersValid(gray, "") {
+		return nil, InvalidArgsError{msg: fmt.Sprintf("invalid gray letters %q", c.Gray)}
+	}
+	for _, y := range c.Yellow {
+		if !letterValid(y.Letter) || y.Position < 1 || y.Position > n {
+			return nil, InvalidArgsError{msg: fmt.Sprintf("invalid yellow letter %c:%d", y.Letter, y.Position)}
+		}
+	}
+	c.Green, c.Gray = green, gray
+	return append([]string{"suggest"}, c.args()...), nil
+}
+
+func listArgs(list string) ([]string, error) {
+	if list != "all" && list != "answers" {
+		return nil, InvalidArgsError{msg: fmt.Sprintf("invalid word list %q", list)}
+	}
+	return []string{"list", list}, nil
+}
+
+func sessionArgs(target string) ([]string, error) {
+	if target == "" {
+		return []string{"session"}, nil
+	}
+	target, err := normalizeWord(target)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"session", "-t", target}, nil
+}
+
+// sessionLine is the line a coaching session reads for a guess: the word
+// alone, or the word and its colors when the session has no target
+func sessionLine(f Feedback) (string, error) {
+	if f.Colors == "" {
+		return normalizeWord(f.Word)
+	}
+	line, err := normalizeFeedback([]Feedback{f})
+	if err != nil {
+		return "", err
+	}
+	return line[0], nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type AuthConfig struct {
+	APIKeys map[string]string `toml:"api_keys" comment:"Require one of these API keys, by name, on every request. The names show up\nin logs (as key) and in the wbot_api_key_requests_total metric"`
+	KeyFile string            `toml:"key_file" comment:"More API keys, with a name and a key per line, separated by whitespace"`
+	JWT     JWTConfig         `toml:"jwt" comment:"Accept JWTs signed by one of the keys published at jwks_url"`
+	Limits  LimitsConfig      `toml:"limits" comment:"Limits on requests for each API key or JWT subject, kept in memory per server"`
+}
+
+// authenticator accepts either an API key or a JWT, if either is configured
+type authenticator struct {
+	keys apiKeys
+	jwt  *jwtValidator
+}
+
+func newAuthenticator(config AuthConfig) (*authenticator, error) {
+	keys, err := loadAPIKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	v, err := newJWTValidator(config.JWT)
+	if err != nil {
+		return nil, err
+	}
+	return &authenticator{keys: keys, jwt: v}, nil
+}
+
+func (a *authenticator) enabled() bool {
+	return len(a.keys) > 0 || a.jwt != nil
+}
+
+// authenticate checks the X-API-Key header or bearer token, returning a
+// context naming the key or subject
+func (a *authenticator) authenticate(ctx context.Context, apiKey, authorization string) (context.Context, bool) {
+	token, bearer := strings.CutPrefix(authorization, "Bearer ")
+	if apiKey == "" && bearer && a.jwt != nil && strings.Count(token, ".") == 2 {
+		sub, err := a.jwt.subject(token)
+		if err != nil {
+			return ctx, false
+		}
+		return withSubject(ctx, sub), true
+	}
+
+	if apiKey == "" {
+		apiKey = token
+	}
+	name, ok := a.keys.lookup(apiKey)
+	if !ok {
+		return ctx, false
+	}
+	keyRequests.WithLabelValues(name).Inc()
+	return withAPIKey(ctx, name), true
+}
+
+// require rejects unauthenticated requests
+func (a *authenticator) require(h http.Handler) http.Handler {
+	if !a.enabled() {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := a.authenticate(r.Context(), r.Header.Get("X-API-Key"), r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httpError(w, "Missing or invalid API key or token", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// grpc does the same for the x-api-key and authorization metadata of gRPC
+// calls
+func (a *authenticator) grpc(ctx context.Context) (context.Context, error) {
+	if !a.enabled() {
+		return ctx, nil
+	}
+
+	var apiKey, authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-api-key"); len(v) > 0 {
+			apiKey = v[0]
+		}
+		if v := md.Get("authorization"); len(v) > 0 {
+			authorization = v[0]
+		}
+	}
+
+	ctx, ok := a.authenticate(ctx, apiKey, authorization)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "missing or invalid API key or token")
+	}
+	return ctx, nil
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type Guess struct {
@@ -26,22 +32,109 @@ type WordReport struct {
 }
 
 type Engine interface {
-	Solve(word string) ([]WordReport, error)
-	Coach(word string, guesses []string) (*WordReport, error)
-	WordList() ([]string, error)
+	Solve(ctx context.Context, word string) ([]WordReport, error)
+	SolveStream(ctx context.Context, word string, emit ReportFunc) error
+	Coach(ctx context.Context, word string, guesses []string) (*WordReport, error)
+	CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error)
+	CoachSession(ctx context.Context, word string) (CoachSession, error)
+	Suggest(ctx context.Context, c Constraints) (*Suggestion, error)
+	WordList(ctx context.Context, list string) ([]string, error)
+	Close()
 }
 
 type BotConfig struct {
-	ExecPath           string `toml:"exec_path"`
-	IndexPath          string `toml:"index_path"`
-	MaxConcurrentUsers int    `toml:"max_concurrent_users"`
-	SolveTimeout       int    `toml:"solve_timeout"`
+	Name               string `toml:"name" comment:"Name of the engine in logs, metrics and the X-Wbot-Engine header"`
+	ExecPath           string `toml:"exec_path" comment:"Engine executable. Words are lowercased by words.language and checked to be\nletters of words.scripts, as many as words.lengths allows, before they're passed to it, and guesses follow a --"`
+	IndexPath          string `toml:"index_path" comment:"Passed to the engine as WORDSMITH_INDEX. The best opening guess is saved\nnext to it with an .opener suffix"`
+	GrpcAddr           string `toml:"grpc_addr" comment:"Use a remote engine speaking wbotpb/wbot.proto instead of exec_path"`
+	WasmPath           string `toml:"wasm_path" comment:"Run the engine compiled to WASI in-process instead of exec_path"`
+	WordlistPath       string `toml:"wordlist_path" comment:"Read the word list from this file, with a word per line or as a JSON array,\ninstead of asking the engine for it"`
+	AnswersPath        string `toml:"answers_path" comment:"Read the possible answers from this file too; every word is an answer if\nunset. Only used with wordlist_path"`
+	Builtin            bool   `toml:"builtin" comment:"Always use the builtin solver and its small embedded word list"`
+	Fallback           bool   `toml:"fallback" comment:"Serve requests from the builtin solver if the engine fails"`
+	MaxConcurrentUsers int    `toml:"max_concurrent_users" comment:"Number of engine invocations run at once"`
+	SolveWorkers       int    `toml:"solve_workers" comment:"Workers of their own for solves, coaching and loading word lists; these\nshare max_concurrent_users if 0"`
+	CoachWorkers       int    `toml:"coach_workers"`
+	AdminWorkers       int    `toml:"admin_workers"`
+	MaxSessions        int    `toml:"max_sessions" comment:"Maximum number of concurrent /ws/coach sessions; unlimited if 0"`
+	MaxQueue           int    `toml:"max_queue" comment:"Reject requests that would queue behind this many others with 429; unbounded\nif 0"`
+	Prewarm            int    `toml:"prewarm" comment:"Number of idle engine processes to keep started with the index loaded"`
+	SolveShards        int    `toml:"solve_shards" comment:"Split each solve across this many engine processes"`
+	SolveTimeout       int    `toml:"solve_timeout" comment:"Milliseconds the engine may run for a solve or a coaching request, not\ncounting time spent queued"`
 	CoachTimeout       int    `toml:"coach_timeout"`
+	QueueTimeout       int    `toml:"queue_timeout" comment:"Milliseconds a request may wait for a worker; the engine timeout if 0"`
+	BreakerThreshold   int    `toml:"breaker_threshold" comment:"Fail fast for breaker_cooldown milliseconds after this many consecutive\nengine failures; disabled if 0"`
+	BreakerCooldown    int    `toml:"breaker_cooldown"`
+	SlowThreshold      int    `toml:"slow_threshold" comment:"Log engine runs that take longer than this many milliseconds, and append\nthem as JSON lines to slow_log if set; disabled if 0"`
+	SlowLog            string `toml:"slow_log"`
+	StallTimeout       int    `toml:"stall_timeout" comment:"Give up on the engine once it has written nothing for this many\nmilliseconds; disabled if 0"`
+	RunAs              string `toml:"run_as" comment:"Run engine processes as this \"user\" or \"user:group\""`
+	ArgsFromStdin      bool   `toml:"args_from_stdin" comment:"Pass arguments to the engine as a JSON array on stdin instead of its\ncommand line"`
+
+	MaxOutput map[string]int64 `toml:"max_output" comment:"Bytes each operation may write to stdout; 1 MiB by default, 64 MiB for\nsession"`
+
+	Sandbox SandboxConfig `toml:"sandbox" comment:"Run the engine under bubblewrap without network access. Linux only"`
+	Limits  ProcessLimits `toml:"limits" comment:"Limit the memory and CPUs of each engine process. Linux only"`
+
+	Backends       []BotConfig `toml:"backends" comment:"Engines to fail over between, in order of preference, instead of this one.\nThey inherit the settings of this table unless they override them"`
+	MaxFailures    int         `toml:"max_failures" comment:"Consecutive errors after which a backend is considered unhealthy"`
+	HealthInterval int         `toml:"health_interval" comment:"Milliseconds between probes of an unhealthy backend"`
+
+	Canary        *BotConfig `toml:"canary" comment:"Engine to route canary_percent of /solve and /coach traffic to"`
+	CanaryPercent float64    `toml:"canary_percent" comment:"Percentage of /solve and /coach traffic routed to [engine.canary]"`
+
+	Shadow    *BotConfig `toml:"shadow" comment:"Engine to mirror all traffic to; disagreements are logged, and appended as\nJSON lines to shadow_log if set"`
+	ShadowLog string     `toml:"shadow_log" comment:"Append disagreements with [engine.shadow] as JSON lines to this file"`
+
+	Retry RetryConfig `toml:"retry" comment:"Retry engine invocations that crash or produce invalid output"`
+	Cache CacheConfig `toml:"cache" comment:"Cache solve and coach results"`
+
+	// dictionary is the one in [dictionaries] the engine runs with, if any
+	dictionary string
+}
+
+type RetryConfig struct {
+	MaxAttempts int    `toml:"max_attempts"`
+	Backoff     int    `toml:"backoff" comment:"Milliseconds to wait before the first retry, doubling after each one"`
+	On          string `toml:"on" comment:"exit (nonzero exit status), decode (invalid output) or any"`
 }
 
 type Bot struct {
 	config BotConfig
-	work   chan func()
+	sched  *scheduler
+	pools  map[string]*scheduler
+	wasm   *wasmModule
+	warm   chan *warmProcess
+	done   chan struct{}
+
+	sessions chan struct{}
+
+	breaker *circuitBreaker
+	cache   resultCache
+	slow    *slowLog
+	cred    *syscall.Credential
+	bwrap   string
+	seccomp []byte
+	limits  *processLimiter
+	flights singleflight.Group
+
+	// timeouts can be changed by reloading the config
+	timeouts atomic.Pointer[timeoutConfig]
+	opener   atomic.Pointer[string]
+	files    atomic.Pointer[engineFiles]
+	// unusable says what's wrong with a replaced executable
+	unusable atomic.Pointer[string]
+	// replaced is set once the executable or index changes, after which
+	// solves precomputed with the old ones aren't served
+	replaced atomic.Bool
+
+	errMu     sync.Mutex
+	lastErr   string
+	lastErrAt time.Time
+
+	answersOnce sync.Once
+	answerList  []string
+	answersErr  error
 }
 
 type TimeoutError string
@@ -50,6 +143,75 @@ func (err TimeoutError) Error() string {
 	return string(err)
 }
 
+type DecodeError struct {
+	Err error
+}
+
+func (err DecodeError) Error() string {
+	return fmt.Sprintf("invalid engine output: %v", err.Err)
+}
+
+func (err DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// EngineError carries the end of what a failed engine process wrote to
+// stderr, for error reports
+type EngineError struct {
+	Err    error
+	Stderr string
+}
+
+func (err EngineError) Error() string {
+	return err.Err.Error()
+}
+
+func (err EngineError) Unwrap() error {
+	return err.Err
+}
+
+func withStderr(err error, stderr *tailBuffer) error {
+	if err == nil || stderr == nil {
+		return err
+	}
+	if tail := stderr.String(); tail != "" {
+		return EngineError{Err: err, Stderr: tail}
+	}
+	return err
+}
+
+// engineStderr returns the engine stderr attached to err, if any
+func engineStderr(err error) string {
+	var engineErr EngineError
+	if errors.As(err, &engineErr) {
+		return engineErr.Stderr
+	}
+	return ""
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
 func (config BotConfig) validateExec() error {
 	info, err := os.Stat(config.ExecPath)
 	if err != nil {
@@ -73,99 +235,469 @@ func (config BotConfig) validateExec() error {
 	return nil
 }
 
-func NewBot(config BotConfig) (bot *Bot, err error) {
-	err = config.validateExec()
+func NewBot(config BotConfig) (*Bot, error) {
+	bot, err := newBot(config)
+	if err != nil {
+		return nil, err
+	}
+
+	registerBot(bot)
+	if bot.wasm == nil {
+		for i := 0; i < config.Prewarm; i++ {
+			go bot.spawnWarm()
+		}
+	}
+	go bot.loadOpener()
+	go bot.hashFiles()
+	return bot, nil
+}
+
+// newBot sets up an engine and its workers, without prewarming processes or
+// computing the opener
+func newBot(config BotConfig) (bot *Bot, err error) {
+	var wasm *wasmModule
+	if config.WasmPath != "" {
+		wasm, err = newWasmModule(config)
+	} else {
+		err = config.validateExec()
+	}
+
+	maxSessions := config.MaxSessions
+	if maxSessions <= 0 {
+		maxSessions = config.MaxConcurrentUsers
+	}
+
+	var cred *syscall.Credential
+	if err == nil && wasm == nil {
+		cred, err = config.credential()
+	}
+
+	var bwrap string
+	var seccomp []byte
+	if err == nil && wasm == nil && config.Sandbox.Enabled {
+		if bwrap, err = config.Sandbox.bwrap(); err == nil {
+			seccomp, err = seccompFilter()
+		}
+	}
+
+	var limits *processLimiter
+	if err == nil && wasm == nil {
+		limits, err = newProcessLimiter(config.Limits)
+	}
+
+	var slow *slowLog
 	if err == nil {
-		bot = &Bot{config: config, work: make(chan func())}
-		for i := 0; i < config.MaxConcurrentUsers; i++ {
-			go bot.worker()
+		slow, err = newSlowLog(config)
+	}
+
+	if err == nil {
+		bot = &Bot{
+			config: config,
+			sched:  newScheduler(config.displayName(), config.MaxQueue),
+			wasm:   wasm,
+			warm:   make(chan *warmProcess, config.Prewarm),
+			done:   make(chan struct{}),
+
+			breaker:  newCircuitBreaker(config),
+			cache:    newResultCache(config.Cache),
+			slow:     slow,
+			cred:     cred,
+			bwrap:    bwrap,
+			seccomp:  seccomp,
+			limits:   limits,
+			sessions: make(chan struct{}, maxSessions),
 		}
+		bot.timeouts.Store(config.timeouts())
+		bot.startPools()
 	}
 
 	return
 }
 
 func (b *Bot) Close() {
-	close(b.work)
+	b.sched.close()
+	for _, s := range b.pools {
+		s.close()
+	}
+	close(b.done)
+	b.drainWarm()
+	if b.cache != nil {
+		b.cache.Close()
+	}
+	if b.wasm != nil {
+		b.wasm.Close()
+	}
+	b.slow.Close()
 }
 
-func (bot *Bot) worker() {
+func (bot *Bot) worker(s *scheduler) {
 	for {
-		f, ok := <-bot.work
+		t, ok := s.next()
 		if !ok {
 			break
 		}
-		f()
+		err := t.run()
+		s.finished()
+		t.done <- err
 	}
 }
 
-func (b *Bot) execAtom(timeout int, v any, args ...string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Millisecond)
+// execResult describes how an engine process went, for the slow log
+type execResult struct {
+	output   int64
+	exitCode int
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (b *Bot) execAtom(ctx context.Context, timeout int, res *execResult, v any, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, b.config.ExecPath, args...)
-	cmd.Env = append(cmd.Env, fmt.Sprintf("WORDSMITH_INDEX=%s", b.config.IndexPath))
+	res.exitCode = -1
+	if b.wasm != nil {
+		return b.wasm.run(ctx, res, b.opener.Load(), b.config.maxOutput(args[0]), v, args...)
+	}
 
-	reader, err := cmd.StdoutPipe()
+	var cmd *exec.Cmd
+	var reader io.Reader
+	var stderr *tailBuffer
+
+	_, starting := tracer.Start(ctx, "engine start")
+	p, err := b.stdinProcess()
 	if err != nil {
+		endSpan(starting, err)
 		return err
 	}
+	if p != nil {
+		stop := context.AfterFunc(ctx, func() { killGroup(p.cmd) })
+		defer stop()
+
+		if err := p.send(args); err != nil {
+			p.kill()
+			endSpan(starting, err)
+			return err
+		}
+		cmd, reader, stderr = p.cmd, p.stdout, p.stderr
+	} else {
+		var stdout io.Reader
+		cmd, err = b.command(ctx, args...)
+		if err == nil {
+			stderr = &tailBuffer{max: 4096}
+			cmd.Stderr = stderr
+			stdout, err = cmd.StdoutPipe()
+		}
+		if err == nil {
+			err = b.start(cmd)
+		}
+		if err != nil {
+			endSpan(starting, err)
+			return err
+		}
+		reader = stdout
+	}
+	starting.End()
+
+	counter := &countingReader{r: b.watchStalls(reader)}
+	defer func() {
+		res.output = counter.n
+		if cmd.ProcessState != nil {
+			res.exitCode = cmd.ProcessState.ExitCode()
+		}
+	}()
 
-	limiter := io.LimitReader(reader, 1024 * 1024)
+	limit := b.config.maxOutput(args[0])
+	limiter := io.LimitReader(counter, limit)
 	decoder := json.NewDecoder(limiter)
 
-	if err := cmd.Start(); err != nil {
-		return err
-	}
+	_, decoding := tracer.Start(ctx, "engine decode")
+	err = decodeInto(decoder, v)
+	endSpan(decoding, err)
+	if err != nil {
+		if ctxErr := ctx.Err(); errors.Is(ctxErr, context.Canceled) {
+			return ctxErr
+		} else if ctxErr != nil {
+			return TimeoutError(fmt.Sprintf("engine ran for longer than %dms", timeout))
+		}
 
-	if err := decoder.Decode(v); err != nil {
-		if ctxErr := ctx.Err(); ctxErr != nil {
-			return TimeoutError("timeout")
+		var stalled StalledError
+		if errors.As(err, &stalled) {
+			terminate(cmd)
+			cmd.Wait()
+			return withStderr(stalled, stderr)
 		}
-		return err
+
+		var streamErr StreamError
+		if errors.As(err, &streamErr) {
+			killGroup(cmd)
+			cmd.Wait()
+			return streamErr
+		}
+
+		if counter.n >= limit {
+			// Count the rest of the output for the log, the timeout
+			// still applies if the engine doesn't stop
+			io.Copy(io.Discard, counter)
+			cmd.Wait()
+			return withStderr(TruncatedError{Size: counter.n, Limit: limit}, stderr)
+		}
+
+		// A crashed engine usually shows up as truncated output first,
+		// the exit status is the more useful error
+		var exitErr *exec.ExitError
+		if waitErr := cmd.Wait(); errors.As(waitErr, &exitErr) {
+			return withStderr(exitError(exitErr, exitErr.ExitCode()), stderr)
+		}
+		return withStderr(DecodeError{err}, stderr)
 	}
 
-	return cmd.Wait()
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = exitError(exitErr, exitErr.ExitCode())
+	}
+	return withStderr(err, stderr)
 }
 
-func (b *Bot) exec(timeout int, v any, args ...string) (err error) {
-	wg := sync.WaitGroup{}
+func (rc RetryConfig) shouldRetry(err error, attempt int) bool {
+	if err == nil || attempt >= rc.MaxAttempts || requestFault(err) {
+		return false
+	}
 
-	task := func() {
-		err = b.execAtom(timeout, v, args...)
-		wg.Done()
+	var exitErr *exec.ExitError
+	var decodeErr DecodeError
+	switch {
+	case errors.As(err, &exitErr):
+		return rc.On != "decode"
+	case errors.As(err, &decodeErr):
+		return rc.On != "exit"
 	}
 
-	wg.Add(1)
-	select {
-	case b.work <- task:
-		wg.Wait()
+	return false
+}
+
+func (b *Bot) exec(ctx context.Context, timeout int, v any, args ...string) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = b.execOnce(ctx, timeout, v, args...)
+		if !b.config.Retry.shouldRetry(err, attempt) {
+			return
+		}
+
+		delay := time.Duration(b.config.Retry.Backoff<<(attempt-1)) * time.Millisecond
+		slog.WarnContext(ctx, "Engine failed, retrying", "engine", b.config.displayName(), "attempt", attempt, "max_attempts", b.config.Retry.MaxAttempts, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (b *Bot) execOnce(ctx context.Context, timeout int, v any, args ...string) (err error) {
+	ctx, span := engineSpan(ctx, "engine exec", b.config.displayName(), args)
+	defer func() { endSpan(span, err) }()
+
+	if err = b.breaker.allow(); err != nil {
+		return
+	}
+
+	_, queued := tracer.Start(ctx, "engine queue")
+	defer queued.End()
+
+	submitted := time.Now()
+	t := &task{
+		client:   clientFrom(ctx),
+		priority: priorityFrom(ctx),
+		run: func() error {
+			queued.End()
+			start := time.Now()
+			if priorityFrom(ctx) != background {
+				queueLatency.observe(engineOp(b.config.displayName(), args), start.Sub(submitted))
+			}
+			var res execResult
+			err := b.execAtom(ctx, timeout, &res, v, args...)
+			elapsed := time.Since(start)
+			var truncated TruncatedError
+			if errors.As(err, &truncated) {
+				slog.WarnContext(ctx, "Engine output truncated", "engine", b.config.displayName(), "size", truncated.Size, "limit", truncated.Limit)
+			}
+			observeExec(b.config.displayName(), args, elapsed, err)
+			b.slow.record(ctx, b.config.displayName(), args, start.Sub(submitted), elapsed, res, err)
+			b.breaker.record(err)
+			b.recordError(err)
+			return err
+		},
+		done: make(chan error, 1),
+	}
+
+	s := b.pool(args)
+	if err = s.submit(t); err != nil {
 		return
+	}
 
-	case <- time.After(time.Duration(timeout) * time.Millisecond):
-		err = TimeoutError("timeout waiting for resources")
+	if t.priority == background {
+		select {
+		case err = <-t.done:
+		case <-b.done:
+			err = errors.New("engine closed")
+		}
 		return
 	}
+
+	// Time spent queued doesn't count towards the engine's own timeout
+	wait := b.timeouts.Load().queue
+	if wait <= 0 {
+		wait = timeout
+	}
+	timer := time.NewTimer(time.Duration(wait) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case err = <-t.done:
+	case <-timer.C:
+		if s.remove(t) {
+			engineTimeouts.WithLabelValues(b.config.displayName(), "queue").Inc()
+			return TimeoutError(fmt.Sprintf("queued for longer than %dms", wait))
+		}
+		err = <-t.done
+	case <-ctx.Done():
+		// The engine is killed if it already started
+		if s.remove(t) {
+			return ctx.Err()
+		}
+		err = <-t.done
+	}
+	return
+}
+
+// execCached is exec for invocations whose output only depends on args;
+// results are cached, and concurrent identical invocations share one engine
+// run
+func (b *Bot) execCached(ctx context.Context, timeout int, v any, args ...string) error {
+	key := b.cacheKey(args)
+	if b.cache != nil {
+		if data, ok := b.cache.get(key); ok {
+			return decodeInto(json.NewDecoder(bytes.NewReader(data)), v)
+		}
+	}
+
+	// Requests shouldn't wait on lower priority work, which is only picked up
+	// when there is nothing more urgent
+	flight := fmt.Sprintf("%v %s", priorityFrom(ctx), key)
+
+	leader := false
+	data, err, _ := b.flights.Do(flight, func() (any, error) {
+		leader = true
+		return b.execShared(ctx, key, timeout, v, args...)
+	})
+	if leader {
+		return err
+	}
+
+	var streamErr StreamError
+	if errors.As(err, &streamErr) || errors.Is(err, context.Canceled) && ctx.Err() == nil {
+		// The leader's client went away, not the engine
+		return b.execCached(ctx, timeout, v, args...)
+	}
+	if err != nil {
+		return err
+	}
+	return decodeInto(json.NewDecoder(bytes.NewReader(data.([]byte))), v)
+}
+
+func (b *Bot) execShared(ctx context.Context, key string, timeout int, v any, args ...string) ([]byte, error) {
+	// Streamed reports aren't kept, so collect them to share them
+	target := v
+	if s, ok := v.(*reportStream); ok && s.emit != nil {
+		var reports []WordReport
+		target = &reportStream{emit: func(turn int, report WordReport) error {
+			reports = append(reports[:turn], report)
+			return s.emit(turn, report)
+		}}
+		v = &reports
+	}
+
+	if err := b.run(ctx, timeout, target, args...); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if b.cache != nil {
+		b.cache.add(key, data)
+	}
+	return data, nil
 }
 
-func (b *Bot) Solve(word string) ([]WordReport, error) {
+func (b *Bot) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	args, err := solveArgs(word)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []WordReport
-	err := b.exec(b.config.SolveTimeout, &result, "solve", "-t", word)
+	err = b.execCached(ctx, b.timeouts.Load().solve, &result, args...)
 	return result, err
 }
 
-func (b *Bot) Coach(word string, guesses []string) (*WordReport, error) {
+func (b *Bot) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	args, err := solveArgs(word)
+	if err != nil {
+		return err
+	}
+	return b.execCached(ctx, b.timeouts.Load().solve, &reportStream{emit: emit}, args...)
+}
+
+func (b *Bot) Coach(ctx context.Context, word string, guesses []string) (*WordReport, error) {
+	args, err := coachArgs(word, guesses)
+	if err != nil {
+		return nil, err
+	}
+
+	var result WordReport
+	err = b.execCached(ctx, b.timeouts.Load().coach, &result, args...)
+	return &result, err
+}
+
+func (b *Bot) CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error) {
+	args, err := coachFeedbackArgs(feedback)
+	if err != nil {
+		return nil, err
+	}
+
 	var result WordReport
+	err = b.execCached(ctx, b.timeouts.Load().coach, &result, args...)
+	return &result, err
+}
 
-	args := []string{"coach", "-t", word}
-	args = append(args, guesses...)
+func (b *Bot) Suggest(ctx context.Context, c Constraints) (*Suggestion, error) {
+	args, err := suggestArgs(c)
+	if err != nil {
+		return nil, err
+	}
 
-	err := b.exec(b.config.CoachTimeout, &result, args...)
+	var result Suggestion
+	err = b.exec(ctx, b.timeouts.Load().coach, &result, args...)
 	return &result, err
 }
 
-func (b *Bot) WordList() ([]string, error) {
+func (b *Bot) WordList(ctx context.Context, list string) ([]string, error) {
+	args, err := listArgs(list)
+	if err != nil {
+		return nil, err
+	}
 	var words []string
-	err := b.exec(1000, &words, "list", "all")
+	err = b.exec(ctx, 1000, &words, args...)
 	return words, err
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (err CircuitOpenError) Error() string {
+	return "engine circuit breaker open"
+}
+
+type BreakerStatus struct {
+	State      string `json:"state"`
+	Failures   int    `json:"failures"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     string
+	openedAt  time.Time
+}
+
+var botsMu sync.Mutex
+var bots = map[string]*Bot{}
+
+func newCircuitBreaker(config BotConfig) *circuitBreaker {
+	if config.BreakerThreshold <= 0 {
+		return nil
+	}
+
+	cooldown := config.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = 30000
+	}
+
+	return &circuitBreaker{
+		threshold: config.BreakerThreshold,
+		cooldown:  time.Duration(cooldown) * time.Millisecond,
+		state:     "closed",
+	}
+}
+
+func (cb *circuitBreaker) allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == "closed" {
+		return nil
+	}
+
+	elapsed := time.Since(cb.openedAt)
+	if elapsed < cb.cooldown {
+		return CircuitOpenError{RetryAfter: cb.cooldown - elapsed}
+	}
+
+	// Let a single probe through per cool-down window
+	cb.state = "half-open"
+	cb.openedAt = time.Now()
+	return nil
+}
+
+func (cb *circuitBreaker) record(err error) {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// The client went away or sent a bad request, not the engine's fault
+	var streamErr StreamError
+	if errors.As(err, &streamErr) || errors.Is(err, context.Canceled) || requestFault(err) {
+		return
+	}
+
+	if err == nil {
+		cb.failures = 0
+		cb.state = "closed"
+		return
+	}
+
+	cb.failures++
+	if cb.state == "half-open" || cb.failures >= cb.threshold {
+		cb.state = "open"
+		cb.openedAt = time.Now()
+	}
+}
+
+func (cb *circuitBreaker) status() BreakerStatus {
+	if cb == nil {
+		return BreakerStatus{State: "disabled"}
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := BreakerStatus{State: cb.state, Failures: cb.failures}
+	if remaining := cb.cooldown - time.Since(cb.openedAt); cb.state != "closed" && remaining > 0 {
+		status.RetryAfter = int(remaining.Milliseconds())
+	}
+	return status
+}
+
+func registerBot(b *Bot) {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+	bots[b.config.displayName()] = b
+}
+
+// botNamed is the running engine with the name, or nil
+func botNamed(name string) *Bot {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+	return bots[name]
+}
+
+func breakerStatuses() map[string]BreakerStatus {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	statuses := make(map[string]BreakerStatus, len(bots))
+	for name, b := range bots {
+		statuses[name] = b.breaker.status()
+	}
+	return statuses
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed builtin_words.txt
+var builtinWordList string
+
+const builtinBestCount = 10
+
+type BuiltinEngine struct {
+	words   []string
+	known   map[string]bool
+	opening []Guess
+	once    sync.Once
+}
+
+type FallbackEngine struct {
+	primary  Engine
+	fallback Engine
+}
+
+func NewBuiltinEngine() *BuiltinEngine {
+	words := strings.Fields(builtinWordList)
+	known := make(map[string]bool, len(words))
+	for _, w := range words {
+		known[w] = true
+	}
+
+	return &BuiltinEngine{words: words, known: known}
+}
+
+func filterWords(candidates []string, guess, colors string) []string {
+	var left []string
+	for _, c := range candidates {
+		if wordColors(guess, c) == colors {
+			left = append(left, c)
+		}
+	}
+	return left
+}
+
+func guessEntropy(guess string, candidates []string) float32 {
+	counts := make(map[string]int)
+	for _, c := range candidates {
+		counts[wordColors(guess, c)]++
+	}
+
+	n := float64(len(candidates))
+	h := 0.0
+	for _, k := range counts {
+		p := float64(k) / n
+		h -= p * math.Log2(p)
+	}
+	return float32(h)
+}
+
+func (e *BuiltinEngine) rank(candidates []string) []Guess {
+	if len(candidates) == len(e.words) {
+		e.once.Do(func() {
+			e.opening = e.rankSlow(candidates)
+		})
+		return e.opening
+	}
+
+	return e.rankSlow(candidates)
+}
+
+func (e *BuiltinEngine) rankSlow(candidates []string) []Guess {
+	possible := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		possible[c] = true
+	}
+
+	ranked := make([]Guess, len(e.words))
+	for i, w := range e.words {
+		ranked[i] = Guess{Word: w, Score: guessEntropy(w, candidates)}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return possible[a.Word] && !possible[b.Word]
+	})
+
+	if len(ranked) > builtinBestCount {
+		ranked = ranked[:builtinBestCount]
+	}
+	return ranked
+}
+
+func (e *BuiltinEngine) report(user Guess, best []Guess, target string, candidates []string) WordReport {
+	colors := wordColors(user.Word, target)
+	left := filterWords(candidates, user.Word, colors)
+
+	return WordReport{
+		User:        user,
+		Best:        best,
+		OptionsLeft: left,
+		Eliminated:  int32(len(candidates) - len(left)),
+		Colors:      colors,
+	}
+}
+
+func (e *BuiltinEngine) checkKnown(word string) error {
+	if !e.known[word] {
+		return UnknownWordError{msg: fmt.Sprintf("%s is not in the builtin dictionary", word)}
+	}
+	return nil
+}
+
+func (e *BuiltinEngine) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	return e.solve(word, nil)
+}
+
+func (e *BuiltinEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	_, err := e.solve(word, emit)
+	return err
+}
+
+func (e *BuiltinEngine) solve(word string, emit ReportFunc) ([]WordReport, error) {
+	target := foldWord(word)
+	if err := e.checkKnown(target); err != nil {
+		return nil, err
+	}
+
+	var reports []WordReport
+	candidates := e.words
+	for {
+		best := e.rank(candidates)
+		report := e.report(best[0], best, target, candidates)
+		reports = append(reports, report)
+
+		if emit != nil {
+			if err := emit(len(reports)-1, report); err != nil {
+				return reports, err
+			}
+		}
+
+		if report.User.Word == target {
+			return reports, nil
+		}
+		candidates = report.OptionsLeft
+	}
+}
+
+func (e *BuiltinEngine) Coach(ctx context.Context, word string, guesses []string) (*WordReport, error) {
+	target := foldWord(word)
+	if err := e.checkKnown(target); err != nil {
+		return nil, err
+	}
+
+	candidates := e.words
+	last := foldWord(guesses[len(guesses)-1])
+	for _, g := range guesses[:len(guesses)-1] {
+		g = foldWord(g)
+		candidates = filterWords(candidates, g, wordColors(g, target))
+	}
+
+	user := Guess{Word: last, Score: guessEntropy(last, candidates)}
+	report := e.report(user, e.rank(candidates), target, candidates)
+	return &report, nil
+}
+
+func (e *BuiltinEngine) CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error) {
+	candidates := e.words
+	for _, f := range feedback[:len(feedback)-1] {
+		candidates = filterWords(candidates, f.Word, f.Colors)
+	}
+
+	report := e.feedbackReport(candidates, feedback[len(feedback)-1])
+	return &report, nil
+}
+
+func (e *BuiltinEngine) feedbackReport(candidates []string, f Feedback) WordReport {
+	left := filterWords(candidates, f.Word, f.Colors)
+
+	best := []Guess{}
+	if len(left) > 0 {
+		best = e.rank(left)
+	}
+
+	return WordReport{
+		User:        Guess{Word: f.Word, Score: guessEntropy(f.Word, candidates)},
+		Best:        best,
+		OptionsLeft: left,
+		Eliminated:  int32(len(candidates) - len(left)),
+		Colors:      f.Colors,
+	}
+}
+
+func (e *BuiltinEngine) Suggest(ctx context.Context, c Constraints) (*Suggestion, error) {
+	var candidates []string
+	for _, w := range e.words {
+		if c.match(w) {
+			candidates = append(candidates, w)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return &Suggestion{Best: []Guess{}, OptionsLeft: []string{}}, nil
+	}
+
+	return &Suggestion{Best: e.rank(candidates), OptionsLeft: candidates}, nil
+}
+
+func (e *BuiltinEngine) WordList(ctx context.Context, list string) ([]string, error) {
+	return e.words, nil
+}
+
+func (e *BuiltinEngine) Close() {
+}
+
+// fallBack reports whether the builtin solver should take over from the
+// engine after err, logging that it does. It doesn't for input the engine
+// rejected, which the builtin solver would accept, nor for a full queue, as
+// running the builtin solver instead would get around the backpressure.
+// Neither does it for a client that went away
+func (f *FallbackEngine) fallBack(ctx context.Context, err error) bool {
+	var full QueueFullError
+	if err == nil || ctx.Err() != nil || requestFault(err) || errors.As(err, &full) {
+		return false
+	}
+	slog.WarnContext(ctx, "Engine error, falling back to builtin solver", "err", err)
+	return true
+}
+
+func (f *FallbackEngine) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	result, err := f.primary.Solve(ctx, word)
+	if f.fallBack(ctx, err) {
+		return f.fallback.Solve(ctx, word)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	sent := false
+	err := f.primary.SolveStream(ctx, word, func(turn int, report WordReport) error {
+		sent = true
+		return emit(turn, report)
+	})
+	// Once turns were sent, the builtin solver's can't replace them
+	var streamErr StreamError
+	if !sent && !errors.As(err, &streamErr) && f.fallBack(ctx, err) {
+		return f.fallback.SolveStream(ctx, word, emit)
+	}
+	return err
+}
+
+func (f *FallbackEngine) Coach(ctx context.Context, word string, guesses []string) (*WordReport, error) {
+	result, err := f.primary.Coach(ctx, word, guesses)
+	if f.fallBack(ctx, err) {
+		return f.fallback.Coach(ctx, word, guesses)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error) {
+	result, err := f.primary.CoachFeedback(ctx, feedback)
+	if f.fallBack(ctx, err) {
+		return f.fallback.CoachFeedback(ctx, feedback)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	result, err := f.primary.CoachSession(ctx, word)
+	if f.fallBack(ctx, err) {
+		return f.fallback.CoachSession(ctx, word)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) Suggest(ctx context.Context, c Constraints) (*Suggestion, error) {
+	result, err := f.primary.Suggest(ctx, c)
+	if f.fallBack(ctx, err) {
+		return f.fallback.Suggest(ctx, c)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) WordList(ctx context.Context, list string) ([]string, error) {
+	result, err := f.primary.WordList(ctx, list)
+	if f.fallBack(ctx, err) {
+		return f.fallback.WordList(ctx, list)
+	}
+	return result, err
+}
+
+func (f *FallbackEngine) Close() {
+	f.primary.Close()
+	f.fallback.Close()
+}
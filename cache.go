@@ -0,0 +1,159 @@
+package main
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type CacheConfig struct {
+	MaxEntries  int    `toml:"max_entries" comment:"Results kept in memory; disabled if 0"`
+	TTL         int    `toml:"ttl" comment:"Seconds results are kept; no expiry if 0"`
+	RedisAddr   string `toml:"redis_addr" comment:"Share results between replicas through Redis"`
+	RedisPrefix string `toml:"redis_prefix"`
+	Precompute  bool   `toml:"precompute" comment:"Fill the cache with the solve reports of every answer while workers are idle"`
+
+	PrecomputedPath string `toml:"precomputed_path" comment:"Serve /solve from a file written by wbot-server precompute"`
+
+	RedisPassword     string `toml:"redis_password"`
+	RedisPasswordFile string `toml:"redis_password_file" comment:"Read redis_password from this file instead"`
+}
+
+type CacheStatus struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+
+	RedisHits   int64 `json:"redisHits,omitempty"`
+	RedisMisses int64 `json:"redisMisses,omitempty"`
+	RedisUp     bool  `json:"redisUp,omitempty"`
+}
+
+type resultCache interface {
+	get(key string) ([]byte, bool)
+	add(key string, data []byte)
+	status() CacheStatus
+	Close()
+}
+
+type cacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+type lruCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	maxEntries int
+	ttl        time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newResultCache(config CacheConfig) resultCache {
+	local := newLRUCache(config)
+	switch {
+	case config.RedisAddr != "":
+		return newRedisCache(config, local)
+	case local != nil:
+		return local
+	}
+	return nil
+}
+
+func newLRUCache(config CacheConfig) *lruCache {
+	if config.MaxEntries <= 0 {
+		return nil
+	}
+
+	return &lruCache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: config.MaxEntries,
+		ttl:        time.Duration(config.TTL) * time.Second,
+	}
+}
+
+// Engine output only depends on the arguments, up to case
+func cacheKey(args []string) string {
+	return strings.ToLower(strings.Join(args, " "))
+}
+
+// cacheKey also covers the engine's files once they're hashed, so results
+// from a replaced executable or index aren't served
+func (b *Bot) cacheKey(args []string) string {
+	if files := b.files.Load(); files != nil {
+		return files.version + " " + cacheKey(args)
+	}
+	return cacheKey(args)
+}
+
+func (c *lruCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok {
+		entry := elem.Value.(*cacheEntry)
+		if c.ttl > 0 && time.Now().After(entry.expires) {
+			c.order.Remove(elem)
+			delete(c.entries, key)
+		} else {
+			c.order.MoveToFront(elem)
+			c.hits.Add(1)
+			return entry.data, true
+		}
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+func (c *lruCache) add(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, data: data, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *lruCache) status() CacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStatus{
+		Entries: c.order.Len(),
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+	}
+}
+
+func (c *lruCache) Close() {}
+
+func cacheStatuses() map[string]CacheStatus {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	statuses := make(map[string]CacheStatus, len(bots))
+	for name, b := range bots {
+		if b.cache != nil {
+			statuses[name] = b.cache.status()
+		}
+	}
+	return statuses
+}
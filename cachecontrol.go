@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setDefaultCacheControl sets the Cache-Control header unless the configured
+// policy for the route already did
+func setDefaultCacheControl(w http.ResponseWriter, value string) {
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", value)
+	}
+}
+
+// `max-age=midnight` lasts until the next midnight UTC, when the daily word
+// changes
+func expandCacheControl(value string, now time.Time) string {
+	if !strings.Contains(value, "max-age=midnight") {
+		return value
+	}
+
+	midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	seconds := strconv.Itoa(int(midnight.Sub(now).Seconds()))
+	return strings.ReplaceAll(value, "max-age=midnight", "max-age="+seconds)
+}
+
+func withCacheControl(h http.Handler, value string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", expandCacheControl(value, time.Now()))
+		h.ServeHTTP(w, r)
+	})
+}
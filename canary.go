@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+)
+
+type EngineStats struct {
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+type engineRouter struct {
+	primary Engine
+	canary  Engine
+	percent float64
+
+	mu    sync.Mutex
+	stats map[string]*EngineStats
+}
+
+func newEngineRouter(primary, canary Engine, percent float64) *engineRouter {
+	return &engineRouter{
+		primary: primary,
+		canary:  canary,
+		percent: percent,
+		stats:   make(map[string]*EngineStats),
+	}
+}
+
+// pick chooses the engine for a request: the one of the dictionary chosen for
+// it, or else the canary or the primary
+func (r *engineRouter) pick(ctx context.Context) (string, Engine) {
+	if name := dictionaryFrom(ctx); name != "" {
+		return "dictionary " + name, dictionaries[name].engine
+	}
+	if r.canary != nil && rand.Float64()*100 < r.percent {
+		return "canary", r.canary
+	}
+	return "primary", r.primary
+}
+
+func (r *engineRouter) record(name string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.stats[name]
+	if !ok {
+		s = &EngineStats{}
+		r.stats[name] = s
+	}
+
+	s.Requests++
+	if err != nil {
+		s.Errors++
+	}
+	s.ErrorRate = float64(s.Errors) / float64(s.Requests)
+}
+
+func (r *engineRouter) Stats() map[string]EngineStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]EngineStats, len(r.stats))
+	for name, s := range r.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// Close closes the engines, including those of the dictionaries
+func (r *engineRouter) Close() {
+	r.primary.Close()
+	if r.canary != nil {
+		r.canary.Close()
+	}
+	for _, d := range dictionaries {
+		d.engine.Close()
+	}
+}
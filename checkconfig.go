@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+)
+
+// checkConfig implements -check-config: it loads the config, checks the TLS
+// certificate and each engine's executable and index, and has every engine
+// list its answers, printing a line per check. It reports whether all of them
+// passed
+func checkConfig() bool {
+	ok := true
+	report := func(what string, err error, detail string) {
+		if err != nil {
+			ok = false
+			fmt.Printf("FAIL  %s: %v\n", what, err)
+		} else {
+			fmt.Printf("ok    %s: %s\n", what, detail)
+		}
+	}
+
+	config, err := loadConfig()
+	report("config", err, globalConfigPath)
+	if err != nil {
+		return false
+	}
+	setupWords(config)
+
+	if c := config.Server; c.TLSCert != "" && len(c.ACME.Domains) == 0 {
+		_, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		report("tls", err, c.TLSCert)
+	}
+
+	for _, c := range config.engines() {
+		if len(c.Backends) > 0 {
+			continue
+		}
+		name := c.displayName()
+		if c.Builtin {
+			name = "builtin"
+		}
+		detail, err := checkEngine(c)
+		report("engine "+name, err, detail)
+	}
+	return ok
+}
+
+// checkEngine sets up the engine of config without serving from it, and runs
+// it once
+func checkEngine(config BotConfig) (string, error) {
+	var eng Engine
+	switch {
+	case config.Builtin:
+		return "nothing to check", nil
+	case config.GrpcAddr != "":
+		g, err := NewGrpcEngine(config)
+		if err != nil {
+			return "", err
+		}
+		eng = g
+	default:
+		if _, err := os.Stat(config.IndexPath); err != nil {
+			return "", fmt.Errorf("index: %w", err)
+		}
+
+		// Nothing that writes files
+		config.Prewarm, config.SlowThreshold, config.Cache = 0, 0, CacheConfig{}
+		b, err := newBot(config)
+		if err != nil {
+			return "", err
+		}
+		eng = b
+	}
+	defer eng.Close()
+
+	start := time.Now()
+	answers, err := eng.WordList(context.Background(), "answers")
+	if err != nil {
+		return "", fmt.Errorf("probe: %w", err)
+	}
+	return fmt.Sprintf("%d answers in %v", len(answers), time.Since(start).Round(time.Millisecond)), nil
+}
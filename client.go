@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type clientKey struct{}
+
+// Engine work is scheduled fairly between clients, identified by their
+// address without the port
+func clientHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func withClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func clientFrom(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+func requestContext(r *http.Request) context.Context {
+	return withClient(r.Context(), clientHost(getIP(r)))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+type CompressionConfig struct {
+	Enabled bool     `toml:"enabled" comment:"Compress responses with brotli or gzip if the client accepts it"`
+	MinSize int      `toml:"min_size" comment:"Bytes a response must have to be compressed"`
+	Types   []string `toml:"types" comment:"Content types to compress; JSON, NDJSON and event streams if empty"`
+}
+
+var defaultCompressTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-ndjson",
+	"application/msgpack",
+	"application/x-protobuf",
+	"text/event-stream",
+	"text/plain",
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter holds back the start of a response until it knows whether
+// it is worth compressing
+type compressWriter struct {
+	http.ResponseWriter
+	config   CompressionConfig
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     compressor
+}
+
+func acceptedEncoding(r *http.Request) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		accepted[strings.ToLower(name)] = q > 0
+	}
+
+	switch {
+	case accepted["br"]:
+		return "br"
+	case accepted["gzip"]:
+		return "gzip"
+	}
+	return ""
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if c.decided {
+		if c.enc != nil {
+			return c.enc.Write(p)
+		}
+		return c.ResponseWriter.Write(p)
+	}
+
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.config.MinSize {
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *compressWriter) compressible() bool {
+	h := c.Header()
+	if h.Get("Content-Encoding") != "" || (c.status != 0 && c.status != http.StatusOK) {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return slices.Contains(c.config.Types, mediaType)
+}
+
+func (c *compressWriter) decide(compress bool) error {
+	c.decided = true
+	if compress && c.compressible() {
+		h := c.Header()
+		h.Set("Content-Encoding", c.encoding)
+		h.Del("Content-Length")
+		if c.encoding == "br" {
+			c.enc = brotli.NewWriter(c.ResponseWriter)
+		} else {
+			c.enc = gzip.NewWriter(c.ResponseWriter)
+		}
+	}
+
+	if c.status != 0 {
+		c.ResponseWriter.WriteHeader(c.status)
+	}
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.Write(c.buf)
+	c.buf = nil
+	return err
+}
+
+// Flushing means the response is streamed, so it is compressed regardless of
+// its size so far
+func (c *compressWriter) FlushError() error {
+	if !c.decided {
+		if err := c.decide(true); err != nil {
+			return err
+		}
+	}
+	if c.enc != nil {
+		if err := c.enc.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+func (c *compressWriter) Flush() {
+	c.FlushError()
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *compressWriter) close() {
+	if !c.decided {
+		c.decide(false)
+	}
+	if c.enc != nil {
+		c.enc.Close()
+	}
+}
+
+func compress(h http.Handler, config CompressionConfig) http.Handler {
+	if !config.Enabled {
+		return h
+	}
+	if config.MinSize <= 0 {
+		config.MinSize = 1024
+	}
+	if len(config.Types) == 0 {
+		config.Types = defaultCompressTypes
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r)
+		if encoding == "" || r.Method == "HEAD" || r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, config: config, encoding: encoding}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includeDir is the conf.d directory next to the server config
+func includeDir() string {
+	return filepath.Join(filepath.Dir(globalConfigPath), "conf.d")
+}
+
+// decodeIncludes merges the config files in includeDir into config, in
+// lexical order. Tables are merged key by key, and values and lists replace
+// those of earlier files. Hidden files and files without a config extension,
+// such as editor backups, are skipped
+func decodeIncludes(config *ConfigFile) error {
+	dir := includeDir()
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		format, ok := configFormats[strings.ToLower(filepath.Ext(name))]
+		if e.IsDir() || strings.HasPrefix(name, ".") || !ok {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		slog.Info("Reading server config", "path", path)
+		if err := decodeConfigFile(path, format, config); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
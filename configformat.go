@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// configFormat is the format of the config file: toml, yaml or json. If it's
+// unset, the format is told by the file's extension, defaulting to toml
+var configFormat string
+
+// configFormats are the formats of config files by their extension
+var configFormats = map[string]string{
+	".toml": "toml",
+	".conf": "toml",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+}
+
+func configFileFormat(path string) string {
+	if configFormat != "" {
+		return strings.ToLower(configFormat)
+	}
+	if format, ok := configFormats[strings.ToLower(filepath.Ext(path))]; ok {
+		return format
+	}
+	return "toml"
+}
+
+func decodeConfigFile(path, format string, config *ConfigFile) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return decodeConfig(file, format, config)
+}
+
+// decodeConfig decodes a config file into config. YAML and JSON are converted
+// to TOML first, so every format uses the same keys and the same rules for
+// converting values. Unknown keys are errors, so misspelled settings don't go
+// unnoticed
+func decodeConfig(r io.Reader, format string, config *ConfigFile) error {
+	var doc map[string]any
+	switch format {
+	case "toml":
+		return unknownKeys(toml.NewDecoder(r).DisallowUnknownFields().Decode(config))
+	case "yaml":
+		if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case "json":
+		d := json.NewDecoder(r)
+		d.UseNumber()
+		if err := d.Decode(&doc); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown config format %q, expected toml, yaml or json", format)
+	}
+
+	data, err := toml.Marshal(tomlValue(doc, reflect.TypeOf(config)))
+	if err != nil {
+		return err
+	}
+	if err = toml.NewDecoder(bytes.NewReader(data)).DisallowUnknownFields().Decode(config); err != nil {
+		return fmt.Errorf("%s config: %w", format, unknownKeys(err))
+	}
+	return nil
+}
+
+// unknownKeys names the keys that err, from decoding a config, complains
+// about, as go-toml only does so in its String method
+func unknownKeys(err error) error {
+	var strict *toml.StrictMissingError
+	if !errors.As(err, &strict) {
+		return err
+	}
+	keys := make([]string, len(strict.Errors))
+	for i, e := range strict.Errors {
+		keys[i] = strings.Join(e.Key(), ".")
+	}
+	return fmt.Errorf("unknown settings %s", strings.Join(keys, ", "))
+}
+
+// tomlValue converts a decoded YAML or JSON value to one that encodes to TOML
+// and decodes into t. Nulls are left out, as TOML has none, and whole numbers
+// for float settings are made floats, as TOML tells them apart
+func tomlValue(v any, t reflect.Type) any {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			if value != nil {
+				m[key] = tomlValue(value, keyType(t, key))
+			}
+		}
+		return m
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			if value != nil {
+				m[fmt.Sprint(key)] = tomlValue(value, keyType(t, fmt.Sprint(key)))
+			}
+		}
+		return m
+	case []any:
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			if value != nil {
+				s = append(s, tomlValue(value, elem))
+			}
+		}
+		return s
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return tomlValue(n, t)
+		}
+		f, _ := v.Float64()
+		return f
+	case int:
+		return tomlValue(int64(v), t)
+	case int64:
+		if t != nil && (t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64) {
+			return float64(v)
+		}
+	}
+	return v
+}
+
+// keyType is the type of the value of key in a table decoded into t, or nil if
+// it isn't known
+func keyType(t reflect.Type, key string) reflect.Type {
+	switch {
+	case t == nil:
+		return nil
+	case t.Kind() == reflect.Map:
+		return t.Elem()
+	case t.Kind() == reflect.Struct:
+		for i := range t.NumField() {
+			if tomlKey(t.Field(i)) == key {
+				return t.Field(i).Type
+			}
+		}
+	}
+	return nil
+}
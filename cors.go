@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig lets browsers call the API from other origins. Origins may be
+// "*", or contain a "*" standing in for any subdomain, as in
+// "https://*.example.com"
+type CORSConfig struct {
+	AllowedOrigins   []string `toml:"allowed_origins" comment:"Origins that may call the API from a browser, which may be \"*\" or use \"*\"\nfor any subdomain; disabled if empty"`
+	AllowedMethods   []string `toml:"allowed_methods" comment:"GET and POST if empty"`
+	AllowedHeaders   []string `toml:"allowed_headers" comment:"Authorization, Content-Type, X-API-Key and X-Request-ID if empty"`
+	ExposedHeaders   []string `toml:"exposed_headers" comment:"X-Request-ID, X-Wbot-Engine, X-Wbot-Api-Version, Retry-After and the rate\nlimit and quota headers if empty"`
+	MaxAge           int      `toml:"max_age" comment:"Seconds preflight responses may be cached"`
+	AllowCredentials bool     `toml:"allow_credentials" comment:"Let browsers send cookies and credentials, which \"*\" origins can't"`
+}
+
+var (
+	defaultCORSMethods = []string{"GET", "POST"}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"}
+	defaultCORSExposed = []string{
+		"X-Request-ID", "X-Wbot-Engine", "X-Wbot-Api-Version", "Retry-After",
+		"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+		"X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset",
+	}
+)
+
+func originAllowed(patterns []string, origin string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == origin {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(p, "*"); ok {
+			if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) && len(origin) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cors adds CORS headers to responses to allowed origins, and answers their
+// preflight requests
+func cors(h http.Handler, config CORSConfig) http.Handler {
+	if len(config.AllowedOrigins) == 0 {
+		return h
+	}
+
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	exposed := config.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = defaultCORSExposed
+	}
+	// check rejects allow_credentials with a wildcard origin, which would let
+	// any site make credentialed requests
+	wildcard := slices.Contains(config.AllowedOrigins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+
+		hdr := w.Header()
+		if !wildcard {
+			hdr.Add("Vary", "Origin")
+		}
+		if preflight {
+			hdr.Add("Vary", "Access-Control-Request-Method")
+			hdr.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" || !originAllowed(config.AllowedOrigins, origin) {
+			if preflight {
+				httpError(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if wildcard {
+			hdr.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			hdr.Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			hdr.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			hdr.Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		hdr.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		hdr.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		if config.MaxAge > 0 {
+			hdr.Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
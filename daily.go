@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type DailyConfig struct {
+	Enabled    bool   `toml:"enabled" comment:"Serve the word of the day at /daily; true by default"`
+	Secret     string `toml:"secret" comment:"The word of the day is derived from the date and this secret, so it must be\nthe same on all replicas"`
+	SecretFile string `toml:"secret_file" comment:"Read secret from this file instead"`
+}
+
+type DailyStats struct {
+	Guesses int64 `json:"guesses"`
+	Solves  int64 `json:"solves"`
+}
+
+type DailyResult struct {
+	Date   string     `json:"date"`
+	Guess  string     `json:"guess,omitempty"`
+	Colors string     `json:"colors,omitempty"`
+	Solved bool       `json:"solved"`
+	Stats  DailyStats `json:"stats"`
+}
+
+type dailyPuzzle struct {
+	secret  []byte
+	answers []string
+
+	mu    sync.Mutex
+	date  string
+	stats DailyStats
+}
+
+var daily *dailyPuzzle
+
+// newDailyPuzzle picks the word of the day from answers. Without a secret,
+// anyone could work out the word ahead of time
+func newDailyPuzzle(config DailyConfig, answers []string) (*dailyPuzzle, error) {
+	if config.Secret == "" {
+		return nil, errors.New("daily: empty secret")
+	}
+	if len(answers) == 0 {
+		return nil, errors.New("daily: no answers of the default length")
+	}
+	sorted := slices.Clone(answers)
+	slices.Sort(sorted)
+	return &dailyPuzzle{secret: []byte(config.Secret), answers: sorted}, nil
+}
+
+func (d *dailyPuzzle) answer(date string) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(date))
+	n := binary.BigEndian.Uint64(mac.Sum(nil))
+	return d.answers[n%uint64(len(d.answers))]
+}
+
+func (d *dailyPuzzle) guess(date, guess string) (result DailyResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.date != date {
+		d.date = date
+		d.stats = DailyStats{}
+	}
+
+	result.Date = date
+	if guess != "" {
+		result.Guess = guess
+		result.Colors = wordColors(guess, d.answer(date))
+		result.Solved = result.Colors == strings.Repeat("g", len(result.Colors))
+
+		d.stats.Guesses++
+		if result.Solved {
+			d.stats.Solves++
+		}
+	}
+	result.Stats = d.stats
+	return
+}
+
+func playDaily(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	if daily == nil {
+		httpError(w, "The daily puzzle is disabled", http.StatusNotFound)
+		return
+	}
+
+	r.ParseForm()
+	guess := foldWord(r.Form.Get("g"))
+
+	if guess != "" && (!wordValid(guess) || utf8.RuneCountInString(guess) != wordLengths[0] || !dict.Load().Validate(guess).Guess) {
+		httpError(w, "Invalid guess", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/daily", "ip", ip, "param", "g")
+		return
+	}
+
+	date := time.Now().UTC().Format(time.DateOnly)
+	setDefaultCacheControl(w, "no-store")
+	writeJSON(w, r, daily.guess(date, guess))
+}
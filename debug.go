@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	runtimedebug "runtime/debug"
+	runtimepprof "runtime/pprof"
+	"time"
+)
+
+// DebugTLSConfig serves the debug listener over TLS, requiring operators to
+// present a certificate signed by ClientCA if set
+type DebugTLSConfig struct {
+	Cert     string `toml:"cert"`
+	Key      string `toml:"key"`
+	ClientCA string `toml:"client_ca" comment:"Require client certificates signed by this CA, and move the /admin\nendpoints to debug_addr"`
+}
+
+func debugTLS(config ServerConfig) (*tls.Config, error) {
+	c := config.DebugTLS
+	if c.Cert == "" && c.Key == "" && c.ClientCA == "" {
+		return nil, nil
+	}
+	if config.DebugAddr == "" {
+		return nil, errors.New("debug_tls requires debug_addr")
+	}
+	if c.Cert == "" || c.Key == "" {
+		return nil, errors.New("debug_tls.cert and debug_tls.key must be set")
+	}
+
+	certs, err := newCertReloader(c.Cert, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.getCertificate,
+	}
+	if c.ClientCA != "" {
+		pem, err := os.ReadFile(c.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates found", c.ClientCA)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// serveDebug serves profiling endpoints on their own listener, which should
+// only be reachable by operators, along with anything already on mux.
+// net/http/pprof also registers itself on http.DefaultServeMux, which is why
+// the API doesn't use that
+func serveDebug(addr string, mux *http.ServeMux, tlsConfig *tls.Config, httpConfig HTTPConfig) error {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", dumpGoroutines)
+	mux.HandleFunc("/debug/heapdump", dumpHeap)
+
+	handler := requestIDs(recoverPanics(mux))
+	if l := activatedListener("debug"); l != nil {
+		slog.Info("Serving debug endpoints", "addr", l.Addr(), "tls", tlsConfig != nil)
+		return serve(l, handler, tlsConfig, httpConfig)
+	}
+	slog.Info("Serving debug endpoints", "addr", addr, "tls", tlsConfig != nil)
+	return listenAndServe(addr, handler, tlsConfig, httpConfig)
+}
+
+// dumpGoroutines writes the stack of every goroutine
+func dumpGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// dumpHeap writes a full heap dump, see runtime/debug.WriteHeapDump. The
+// world is stopped while it is written
+func dumpHeap(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+
+	f, err := os.CreateTemp("", "wbot-heapdump-*")
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	runtimedebug.WriteHeapDump(f.Fd())
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="heapdump"`)
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
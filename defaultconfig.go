@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// defaultConfig has every setting at its default. The engine and the daily
+// secret have no default, so they get placeholder paths for the config to be
+// valid
+func defaultConfig() *ConfigFile {
+	config := &ConfigFile{
+		Server: ServerConfig{
+			Port: 8080,
+			Compression: CompressionConfig{
+				MinSize: 1024,
+				Types:   defaultCompressTypes,
+			},
+			CacheControl: map[string]string{},
+			Priority:     map[string]string{},
+			Auth: AuthConfig{
+				APIKeys: map[string]string{},
+				Limits:  LimitsConfig{Keys: map[string]KeyLimit{}},
+			},
+			OIDC: OIDCConfig{
+				GroupsClaim: "groups",
+				Roles:       map[string][]string{},
+			},
+			ACME: ACMEConfig{HTTPAddr: ":80"},
+			CORS: CORSConfig{
+				AllowedMethods: defaultCORSMethods,
+				AllowedHeaders: defaultCORSHeaders,
+				ExposedHeaders: defaultCORSExposed,
+			},
+		},
+		Engine: BotConfig{
+			ExecPath:  "/usr/local/bin/wordsmith",
+			IndexPath: "/etc/wbot/index.txt",
+			MaxOutput: map[string]int64{},
+		},
+		Daily:     DailyConfig{Enabled: true, SecretFile: "/etc/wbot/daily-secret"},
+		Game:      GameConfig{MaxGuesses: 6, SessionTTL: 86400},
+		Words:     WordsConfig{Scripts: []string{"Latin"}, Lengths: []int{5}},
+		Jobs:      JobConfig{Concurrency: 4, MaxPending: 1000, Retention: 3600},
+		Tracing:   TracingConfig{ServiceName: "wbot-server", SampleRatio: 1},
+		Log:       LogConfig{Format: "text", Level: "info"},
+		AccessLog: AccessLogConfig{Format: "combined"},
+
+		Dictionaries: map[string]DictionaryConfig{},
+	}
+	if err := config.check(); err != nil {
+		panic(err)
+	}
+	return config
+}
+
+// printDefaultConfig implements -print-default-config, writing the default
+// config as TOML with the comments of the config structs
+func printDefaultConfig(w io.Writer) error {
+	fmt.Fprintf(w, "# Default config of wbot-server %s\n\n", buildVersion())
+	return toml.NewEncoder(w).Encode(defaultConfig())
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DictionaryConfig is a word list, and the index the engine solves with,
+// that requests can choose with dict= instead of the engine's own
+type DictionaryConfig struct {
+	IndexPath    string `toml:"index_path" comment:"Passed to the engine as WORDSMITH_INDEX instead of engine.index_path"`
+	WordlistPath string `toml:"wordlist_path" comment:"Read the word list from this file instead of asking the engine for it"`
+	AnswersPath  string `toml:"answers_path" comment:"Read the possible answers from this file too; every word is an answer if\nunset"`
+	Lengths      []int  `toml:"lengths" comment:"Numbers of letters its words may have, the first being the default for\nlen=; words.lengths if unset"`
+}
+
+// namedDictionary is a dictionary from [dictionaries], with the engine that
+// runs with its index
+type namedDictionary struct {
+	config BotConfig
+	engine Engine
+	words  atomic.Pointer[Dictionary]
+}
+
+// dictionaries are the dictionaries requests can choose, by name. It isn't
+// changed after setupDictionaries
+var dictionaries = map[string]*namedDictionary{}
+
+type dictionaryKey struct{}
+
+func withDictionary(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, dictionaryKey{}, name)
+}
+
+// dictionaryFrom is the dictionary chosen for a request, or "" for the
+// engine's own
+func dictionaryFrom(ctx context.Context) string {
+	name, _ := ctx.Value(dictionaryKey{}).(string)
+	return name
+}
+
+// wordsFor is the word list of the dictionary chosen for a request
+func wordsFor(ctx context.Context) *Dictionary {
+	return dictionaryWords(dictionaryFrom(ctx))
+}
+
+func dictionaryWords(name string) *Dictionary {
+	if d, ok := dictionaries[name]; ok {
+		return d.words.Load()
+	}
+	return dict.Load()
+}
+
+// dictionaryEngine is the config of the engine for the dictionary name: the
+// engine's own, with the dictionary's index and word lists
+func (config *ConfigFile) dictionaryEngine(name string) BotConfig {
+	c := config.Engine
+	c.Name = fmt.Sprintf("%s (%s)", c.displayName(), name)
+	c.IndexPath = config.Dictionaries[name].IndexPath
+	c.WordlistPath = config.Dictionaries[name].WordlistPath
+	c.AnswersPath = config.Dictionaries[name].AnswersPath
+	c.Canary, c.Shadow = nil, nil
+	c.Cache.PrecomputedPath = ""
+	c.dictionary = name
+	return c
+}
+
+// engines lists every engine in config, including those of the dictionaries
+func (config *ConfigFile) engines() []BotConfig {
+	configs := engineConfigs(config.Engine)
+	for _, name := range slices.Sorted(maps.Keys(config.Dictionaries)) {
+		configs = append(configs, config.dictionaryEngine(name))
+	}
+	return configs
+}
+
+// setupDictionaries starts an engine for each dictionary and loads its words
+func setupDictionaries(config *ConfigFile) error {
+	for _, name := range slices.Sorted(maps.Keys(config.Dictionaries)) {
+		c := config.dictionaryEngine(name)
+		eng, err := newEngine(c)
+		if err != nil {
+			return fmt.Errorf("dictionary %s: %w", name, err)
+		}
+
+		d, err := loadDictionary(eng, c)
+		if err != nil {
+			eng.Close()
+			return fmt.Errorf("dictionary %s: %w", name, err)
+		}
+		nd := &namedDictionary{config: c, engine: eng}
+		nd.words.Store(d)
+		dictionaries[name] = nd
+		slog.Info("Read words", "dictionary", name, "words", len(d.Words), "answers", len(d.Answers))
+		precomputeAll(name, d.Answers)
+	}
+	return nil
+}
+
+// chooseDictionary has the request use the dictionary named by its dict
+// parameter, if any
+func chooseDictionary(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("dict")
+		if name == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := dictionaries[name]; !ok {
+			httpError(w, "Unknown dictionary", http.StatusBadRequest)
+			slog.InfoContext(r.Context(), "Invalid request", "ip", getIP(r), "param", "dict", "dict", name)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(withDictionary(r.Context(), name)))
+	})
+}
+
+// defaultDictionaryRoutes only serve the engine's own dictionary, at its
+// default length
+var defaultDictionaryRoutes = []string{"/daily", "/openers"}
+
+// noDictionary rejects dict= and len= on defaultDictionaryRoutes, rather than
+// answering from another dictionary than was asked for
+func noDictionary(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("dict") != "" || q.Get("len") != "" {
+			httpError(w, "Only the default dictionary and word length are served here", http.StatusBadRequest)
+			slog.InfoContext(r.Context(), "Invalid request", "ip", getIP(r), "param", "dict")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// grpcDictionary does the same for the x-wbot-dict metadata of gRPC calls
+func grpcDictionary(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("x-wbot-dict")) == 0 || md.Get("x-wbot-dict")[0] == "" {
+		return ctx, nil
+	}
+	name := md.Get("x-wbot-dict")[0]
+	if _, ok := dictionaries[name]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown dictionary %q", name)
+	}
+	return withDictionary(ctx, name), nil
+}
+
+// refreshDictionary reloads the word lists from the engine or the files they
+// are read from, for the engine's own dictionary and the others
+func refreshDictionary() (*Dictionary, error) {
+	reloadState.mu.Lock()
+	config := reloadState.started.Engine
+	reloadState.mu.Unlock()
+
+	d, err := loadDictionary(engine, config)
+	if err != nil {
+		return nil, err
+	}
+	dict.Store(d)
+	slog.Info("Read words", "words", len(d.Words), "answers", len(d.Answers))
+
+	for name, nd := range dictionaries {
+		words, err := loadDictionary(nd.engine, nd.config)
+		if err != nil {
+			return nil, fmt.Errorf("dictionary %s: %w", name, err)
+		}
+		nd.words.Store(words)
+		slog.Info("Read words", "dictionary", name, "words", len(words.Words), "answers", len(words.Answers))
+	}
+	return d, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxWordsLimit = 20000
+
+type Dictionary struct {
+	Words    []string
+	Answers  []string
+	LoadedAt time.Time
+
+	guesses map[string]bool
+	answers map[string]bool
+}
+
+type Validation struct {
+	Word   string `json:"word"`
+	Guess  bool   `json:"guess"`
+	Answer bool   `json:"answer"`
+}
+
+// wordSet folds the words as requests are, in case the engine lists them
+// decomposed
+func wordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[foldWord(w)] = true
+	}
+	return set
+}
+
+// loadDictionary reads the word lists from config.WordlistPath and
+// config.AnswersPath if set, and asks eng for them otherwise
+func loadDictionary(eng Engine, config BotConfig) (*Dictionary, error) {
+	if config.WordlistPath != "" {
+		return readDictionary(config)
+	}
+
+	ctx := context.Background()
+	words, err := eng.WordList(ctx, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := eng.WordList(ctx, "answers")
+	if err != nil {
+		slog.Warn("Failed to load answer list, treating all words as answers", "err", err)
+		answers = words
+	}
+	return newDictionary(words, answers), nil
+}
+
+// readDictionary reads the word lists from files, so the engine needn't be
+// run for them. Every word is an answer unless answers_path is set
+func readDictionary(config BotConfig) (*Dictionary, error) {
+	lengths := lengthsFor(config.dictionary)
+	words, err := readWordList(config.WordlistPath, lengths)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := words
+	if config.AnswersPath != "" {
+		if answers, err = readWordList(config.AnswersPath, lengths); err != nil {
+			return nil, err
+		}
+	}
+	return newDictionary(words, answers), nil
+}
+
+func newDictionary(words, answers []string) *Dictionary {
+	return &Dictionary{
+		Words:    words,
+		Answers:  answers,
+		LoadedAt: time.Now(),
+		guesses:  wordSet(words),
+		answers:  wordSet(answers),
+	}
+}
+
+func (d *Dictionary) Validate(word string) Validation {
+	word = foldWord(word)
+	return Validation{
+		Word:   word,
+		Guess:  d.guesses[word],
+		Answer: d.answers[word],
+	}
+}
+
+func validateWord(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	word := r.Form.Get("w")
+
+	if !wordValidFor(r.Context(), word) {
+		httpError(w, "Invalid word", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/validate", "ip", ip, "param", "w")
+		return
+	}
+
+	writeJSON(w, r, wordsFor(r.Context()).Validate(word))
+}
+
+type WordPage struct {
+	Total  int      `json:"total"`
+	Offset int      `json:"offset"`
+	Limit  int      `json:"limit"`
+	Words  []string `json:"words"`
+}
+
+func formInt(r *http.Request, key string, def, min, max int) (int, bool) {
+	s := r.Form.Get(key)
+	if s == "" {
+		return def, true
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, false
+	}
+	return v, true
+}
+
+func listWords(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	offset, ok := formInt(r, "offset", 0, 0, math.MaxInt)
+	if !ok {
+		httpError(w, "Invalid offset", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/words", "ip", ip, "param", "offset")
+		return
+	}
+
+	limit, ok := formInt(r, "limit", 1000, 1, maxWordsLimit)
+	if !ok {
+		httpError(w, "Invalid limit", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/words", "ip", ip, "param", "limit")
+		return
+	}
+
+	d := wordsFor(r.Context())
+	source := d.Words
+	switch r.Form.Get("list") {
+	case "", "all":
+	case "answers":
+		source = d.Answers
+	default:
+		httpError(w, "Invalid list", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/words", "ip", ip, "param", "list")
+		return
+	}
+
+	prefix := foldWord(r.Form.Get("prefix"))
+	contains := foldWord(r.Form.Get("contains"))
+
+	n := lengthFrom(r.Context())
+	matches := []string{}
+	for _, word := range source {
+		if strings.HasPrefix(word, prefix) && strings.Contains(word, contains) && (n == 0 || utf8.RuneCountInString(word) == n) {
+			matches = append(matches, word)
+		}
+	}
+
+	page := WordPage{Total: len(matches), Offset: offset, Limit: limit, Words: []string{}}
+	if offset < len(matches) {
+		page.Words = matches[offset:min(offset+limit, len(matches))]
+	}
+
+	setDefaultCacheControl(w, "public, max-age=3600")
+	writeJSON(w, r, page)
+}
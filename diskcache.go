@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// Precomputed solve reports are stored as a header, followed by an index of
+// fixed-size records sorted by key, followed by the deflated JSON reports
+// that the records point at. The key of a word is the start of its SHA-256,
+// so words of any length fit:
+//
+//	magic   [8]byte
+//	count   uint32
+//	records [count]struct{ key [16]byte; offset, length uint32 }
+//	data    []byte
+const (
+	diskCacheMagic  = "WBOTPC2\x00"
+	diskCacheKeyLen = 16
+	diskCacheRecord = diskCacheKeyLen + 8
+	diskCacheHeader = len(diskCacheMagic) + 4
+)
+
+type diskCache struct {
+	data  []byte
+	count int
+}
+
+func diskCacheKey(word string) (key [diskCacheKeyLen]byte) {
+	sum := sha256.Sum256([]byte(word))
+	copy(key[:], sum[:])
+	return
+}
+
+func writeDiskCache(path string, results map[string][]WordReport) error {
+	words := make([]string, 0, len(results))
+	for word := range results {
+		words = append(words, word)
+	}
+	slices.SortFunc(words, func(a, b string) int {
+		ka, kb := diskCacheKey(a), diskCacheKey(b)
+		return bytes.Compare(ka[:], kb[:])
+	})
+
+	var index, data bytes.Buffer
+	index.WriteString(diskCacheMagic)
+	binary.Write(&index, binary.LittleEndian, uint32(len(words)))
+
+	start := diskCacheHeader + len(words)*diskCacheRecord
+	for _, word := range words {
+		offset := start + data.Len()
+
+		fw, _ := flate.NewWriter(&data, flate.BestCompression)
+		if err := json.NewEncoder(fw).Encode(results[word]); err != nil {
+			return err
+		}
+		fw.Close()
+
+		key := diskCacheKey(word)
+		index.Write(key[:])
+		binary.Write(&index, binary.LittleEndian, uint32(offset))
+		binary.Write(&index, binary.LittleEndian, uint32(start+data.Len()-offset))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".precomputed-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, io.MultiReader(&index, &data))
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func openDiskCache(path string) (*diskCache, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < int64(diskCacheHeader) {
+		return nil, fmt.Errorf("%s is not a precomputed cache", path)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &diskCache{data: data}
+	if magic := string(data[:len(diskCacheMagic)]); magic != diskCacheMagic {
+		c.Close()
+		if strings.HasPrefix(magic, "WBOTPC") {
+			return nil, fmt.Errorf("%s was written by an older version; rerun wbot-server precompute", path)
+		}
+		return nil, fmt.Errorf("%s is not a precomputed cache", path)
+	}
+
+	c.count = int(binary.LittleEndian.Uint32(data[len(diskCacheMagic):]))
+	if diskCacheHeader+c.count*diskCacheRecord > len(data) {
+		c.Close()
+		return nil, fmt.Errorf("%s is truncated", path)
+	}
+	return c, nil
+}
+
+func (c *diskCache) record(i int) []byte {
+	start := diskCacheHeader + i*diskCacheRecord
+	return c.data[start : start+diskCacheRecord]
+}
+
+func (c *diskCache) get(word string) ([]WordReport, bool, error) {
+	key := diskCacheKey(foldWord(word))
+
+	i := sort.Search(c.count, func(i int) bool {
+		return bytes.Compare(c.record(i)[:diskCacheKeyLen], key[:]) >= 0
+	})
+	if i == c.count || !bytes.Equal(c.record(i)[:diskCacheKeyLen], key[:]) {
+		return nil, false, nil
+	}
+
+	rec := c.record(i)
+	offset := int(binary.LittleEndian.Uint32(rec[diskCacheKeyLen:]))
+	length := int(binary.LittleEndian.Uint32(rec[diskCacheKeyLen+4:]))
+	if offset+length > len(c.data) {
+		return nil, false, errors.New("precomputed cache entry out of bounds")
+	}
+
+	var reports []WordReport
+	r := flate.NewReader(bytes.NewReader(c.data[offset : offset+length]))
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(&reports); err != nil {
+		return nil, false, err
+	}
+	return reports, true, nil
+}
+
+func (c *diskCache) Close() {
+	syscall.Munmap(c.data)
+}
+
+// PrecomputedEngine serves solves from a precomputed cache file, and
+// everything else from the engine. Once the executable or index of source,
+// which the file was computed with, is replaced, it serves everything from
+// the engine
+type PrecomputedEngine struct {
+	Engine
+	cache  *diskCache
+	source *Bot
+}
+
+func NewPrecomputedEngine(eng Engine, path string, source *Bot) (*PrecomputedEngine, error) {
+	cache, err := openDiskCache(path)
+	if err != nil {
+		return nil, err
+	}
+	return &PrecomputedEngine{Engine: eng, cache: cache, source: source}, nil
+}
+
+func (e *PrecomputedEngine) get(word string) ([]WordReport, bool, error) {
+	if e.source != nil && e.source.replaced.Load() {
+		return nil, false, nil
+	}
+	return e.cache.get(word)
+}
+
+func (e *PrecomputedEngine) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	if reports, ok, err := e.get(word); ok || err != nil {
+		return reports, err
+	}
+	return e.Engine.Solve(ctx, word)
+}
+
+func (e *PrecomputedEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	if reports, ok, err := e.get(word); err != nil {
+		return err
+	} else if ok {
+		return emitAll(reports, emit)
+	}
+	return e.Engine.SolveStream(ctx, word, emit)
+}
+
+func (e *PrecomputedEngine) Close() {
+	e.Engine.Close()
+	e.cache.Close()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func responseFormat(r *http.Request) string {
+	switch format := r.Form.Get("format"); format {
+	case "json", "msgpack", "protobuf":
+		return format
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/msgpack"), strings.Contains(accept, "application/x-msgpack"):
+		return "msgpack"
+	case strings.Contains(accept, "application/protobuf"), strings.Contains(accept, "application/x-protobuf"):
+		return "protobuf"
+	}
+	return "json"
+}
+
+func toProto(data any) (proto.Message, error) {
+	switch v := data.(type) {
+	case []WordReport:
+		return reportsToProto(v), nil
+	case *WordReport:
+		return reportToProto(v), nil
+	case *Suggestion:
+		return suggestionToProto(v), nil
+	}
+	return nil, fmt.Errorf("no protobuf encoding for %T", data)
+}
+
+func encodeMsgpack(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	// Same field names as the JSON encoding
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeResponse encodes data as JSON, MessagePack or protobuf depending on
+// the format parameter or the Accept header
+func writeResponse(w http.ResponseWriter, r *http.Request, data any) {
+	var body []byte
+	var err error
+
+	w.Header().Add("Vary", "Accept")
+	switch responseFormat(r) {
+	case "msgpack":
+		w.Header().Set("Content-Type", "application/msgpack")
+		body, err = encodeMsgpack(data)
+	case "protobuf":
+		var msg proto.Message
+		if msg, err = toProto(data); err == nil {
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			body, err = proto.Marshal(msg)
+		}
+	default:
+		writeJSON(w, r, data)
+		return
+	}
+
+	if err != nil {
+		w.Header().Del("Content-Type")
+		internalError(w, r, err)
+		return
+	}
+	w.Write(body)
+}
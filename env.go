@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts the name of every environment variable that overrides the
+// config file
+const envPrefix = "WBOT"
+
+// applyEnv overrides config values with environment variables named after
+// their TOML keys, so WBOT_SERVER_PORT sets port in [server] and
+// WBOT_ENGINE_LIMITS_MEMORY_MB sets memory_mb in [engine.limits]. Lists are
+// comma separated, and maps are comma separated key=value pairs
+func applyEnv(config *ConfigFile) error {
+	return applyEnvStruct(reflect.ValueOf(config).Elem(), envPrefix)
+}
+
+// envConfigured reports whether any variable applyEnv looks at is set
+func envConfigured() bool {
+	return envPrefixed(envPrefix + "_")
+}
+
+func envPrefixed(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func applyEnvStruct(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		key := tomlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+
+		name := prefix + "_" + strings.ToUpper(key)
+		if err := applyEnvValue(v.Field(i), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tomlKey is the key of a config struct field, or "" if it has none
+func tomlKey(f reflect.StructField) string {
+	key, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+	if !f.IsExported() || key == "-" {
+		return ""
+	}
+	return key
+}
+
+func applyEnvValue(v reflect.Value, name string) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		return applyEnvStruct(v, name)
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct:
+		if v.IsNil() {
+			if !envPrefixed(name + "_") {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return applyEnvStruct(v.Elem(), name)
+	}
+
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.Slice:
+		err = setEnvSlice(v, s)
+	case reflect.Map:
+		err = setEnvMap(v, s)
+	default:
+		err = setEnvScalar(v, s)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func setEnvSlice(v reflect.Value, s string) error {
+	var items []string
+	if s != "" {
+		items = strings.Split(s, ",")
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setEnvScalar(slice.Index(i), strings.TrimSpace(item)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+func setEnvMap(v reflect.Value, s string) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("can't set %v from the environment", t)
+	}
+
+	m := reflect.MakeMap(t)
+	if s != "" {
+		for _, pair := range strings.Split(s, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", pair)
+			}
+
+			elem := reflect.New(t.Elem()).Elem()
+			if err := setEnvScalar(elem, strings.TrimSpace(value)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(t.Key()), elem)
+		}
+	}
+	v.Set(m)
+	return nil
+}
+
+func setEnvScalar(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("can't set %v from the environment", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+type ErrorReportConfig struct {
+	SentryDSN   string `toml:"sentry_dsn"`
+	Webhook     string `toml:"webhook" comment:"POST reports as JSON to this URL"`
+	Environment string `toml:"environment"`
+
+	SentryDSNFile string `toml:"sentry_dsn_file" comment:"Read sentry_dsn from this file instead"`
+	WebhookFile   string `toml:"webhook_file" comment:"Read webhook from this file instead"`
+}
+
+// ErrorReport is what the webhook receives
+type ErrorReport struct {
+	Time        time.Time         `json:"time"`
+	UUID        string            `json:"uuid"`
+	Environment string            `json:"environment,omitempty"`
+	Error       string            `json:"error"`
+	Stderr      string            `json:"stderr,omitempty"`
+	Panic       bool              `json:"panic,omitempty"`
+	Stack       string            `json:"stack,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+}
+
+var errorReports struct {
+	sentry      bool
+	webhook     string
+	environment string
+}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+func setupErrorReports(config ErrorReportConfig) error {
+	errorReports.webhook = config.Webhook
+	errorReports.environment = config.Environment
+	if config.SentryDSN == "" {
+		return nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         config.SentryDSN,
+		Environment: config.Environment,
+	})
+	if err != nil {
+		return err
+	}
+	errorReports.sentry = true
+	return nil
+}
+
+func flushErrorReports() {
+	if errorReports.sentry {
+		sentry.Flush(5 * time.Second)
+	}
+}
+
+// Parameters that could identify a user or grant access aren't reported
+var sensitiveParams = []string{"token", "key", "secret", "password", "auth", "session"}
+
+func sanitizeParams(values url.Values) map[string]string {
+	params := make(map[string]string, len(values))
+	for name, v := range values {
+		value := strings.Join(v, ",")
+		for _, s := range sensitiveParams {
+			if strings.Contains(strings.ToLower(name), s) {
+				value = "[redacted]"
+			}
+		}
+		params[name] = value
+	}
+	return params
+}
+
+// reportable is false for errors that come with load, rather than bugs or
+// broken engines
+func reportable(err error) bool {
+	switch err.(type) {
+	case TimeoutError, CircuitOpenError, QueueFullError:
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !requestFault(err)
+}
+
+// reportError sends err to Sentry and the webhook, if configured. r may be
+// nil for errors outside of HTTP requests
+func reportError(ctx context.Context, r *http.Request, err error, stack []byte) {
+	if !errorReports.sentry && errorReports.webhook == "" {
+		return
+	}
+
+	report := ErrorReport{
+		Time:        time.Now().UTC(),
+		UUID:        requestIDFrom(ctx),
+		Environment: errorReports.environment,
+		Error:       err.Error(),
+		Panic:       stack != nil,
+		Stack:       string(stack),
+		Stderr:      engineStderr(err),
+	}
+	if r != nil {
+		report.Method = r.Method
+		report.Path = r.URL.Path
+		report.Params = sanitizeParams(r.URL.Query())
+	}
+
+	if errorReports.sentry {
+		sentry.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("uuid", report.UUID)
+			scope.SetContext("request", sentry.Context{
+				"method": report.Method,
+				"path":   report.Path,
+				"params": report.Params,
+			})
+			if report.Stderr != "" {
+				scope.SetContext("engine", sentry.Context{"stderr": report.Stderr})
+			}
+			if report.Panic {
+				scope.SetContext("panic", sentry.Context{"stack": report.Stack})
+			}
+			sentry.CaptureException(err)
+		})
+	}
+
+	if errorReports.webhook != "" {
+		go postErrorReport(report)
+	}
+}
+
+func postErrorReport(report ErrorReport) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+
+	resp, err := webhookClient.Post(errorReports.webhook, "application/json", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			err = fmt.Errorf("webhook returned %s", resp.Status)
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to send error report", "uuid", report.UUID, "err", err)
+	}
+}
+
+// recoverPanics turns a panicking handler into a 500 and an error report,
+// rather than a dropped connection
+func recoverPanics(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil || v == http.ErrAbortHandler {
+				if v != nil {
+					panic(v)
+				}
+				return
+			}
+
+			err := fmt.Errorf("panic: %v", v)
+			stack := debug.Stack()
+			slog.ErrorContext(r.Context(), "Handler panicked", "err", err, "stack", string(stack))
+			reportError(r.Context(), r, err, stack)
+			writeProblem(w, Problem{
+				Type:   problemType("internal"),
+				Status: http.StatusInternalServerError,
+				UUID:   requestIDFrom(r.Context()),
+			})
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
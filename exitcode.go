@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+)
+
+// Exit statuses the engine uses to say why it failed. Anything else nonzero,
+// including exitInternal, is an engine bug
+const (
+	exitInvalidArgs  = 2
+	exitUnknownWord  = 3
+	exitIndexCorrupt = 4
+	exitInternal     = 70
+)
+
+// InvalidArgsError means the engine rejected the arguments it was given
+type InvalidArgsError struct {
+	msg string
+	err error
+}
+
+func (err InvalidArgsError) Error() string {
+	return err.msg
+}
+
+func (err InvalidArgsError) Unwrap() error {
+	return err.err
+}
+
+// UnknownWordError means a word isn't in the engine's dictionary
+type UnknownWordError struct {
+	msg string
+	err error
+}
+
+func (err UnknownWordError) Error() string {
+	return err.msg
+}
+
+func (err UnknownWordError) Unwrap() error {
+	return err.err
+}
+
+// IndexError means the engine's index is missing or corrupt
+type IndexError struct {
+	msg string
+	err error
+}
+
+func (err IndexError) Error() string {
+	return err.msg
+}
+
+func (err IndexError) Unwrap() error {
+	return err.err
+}
+
+// exitError translates an engine's exit status into the errors above, which
+// wrap err so that retries still see the exit, or returns err if the status
+// isn't part of the contract
+func exitError(err error, code int) error {
+	switch code {
+	case exitInvalidArgs:
+		return InvalidArgsError{"engine rejected its arguments", err}
+	case exitUnknownWord:
+		return UnknownWordError{"word is not in the engine's dictionary", err}
+	case exitIndexCorrupt:
+		return IndexError{"engine index is missing or corrupt", err}
+	}
+	return err
+}
+
+// requestFault reports whether err is the request's fault rather than the
+// engine's, so retrying or failing over won't help
+func requestFault(err error) bool {
+	var argsErr InvalidArgsError
+	var wordErr UnknownWordError
+	return errors.As(err, &argsErr) || errors.As(err, &wordErr)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type backend struct {
+	name     string
+	engine   Engine
+	failures int
+	healthy  bool
+}
+
+type FailoverEngine struct {
+	mu          sync.Mutex
+	backends    []*backend
+	maxFailures int
+	done        chan struct{}
+}
+
+func (config BotConfig) displayName() string {
+	switch {
+	case config.Name != "":
+		return config.Name
+	case config.GrpcAddr != "":
+		return config.GrpcAddr
+	case config.WasmPath != "":
+		return config.WasmPath
+	default:
+		return config.ExecPath
+	}
+}
+
+func (config BotConfig) backendConfig(i int) BotConfig {
+	b := config.inherit(config.Backends[i])
+	if b.Name == "" {
+		b.Name = fmt.Sprintf("backend %d (%s)", i, b.displayName())
+	}
+	return b
+}
+
+// canaryConfig and shadowConfig are those of [engine.canary] and
+// [engine.shadow], named after their role unless they have a name, as they
+// usually run the same exec_path as the primary engine
+func (config BotConfig) canaryConfig() BotConfig {
+	return config.roleConfig(*config.Canary, "canary")
+}
+
+func (config BotConfig) shadowConfig() BotConfig {
+	return config.roleConfig(*config.Shadow, "shadow")
+}
+
+func (config BotConfig) roleConfig(c BotConfig, role string) BotConfig {
+	b := config.inherit(c)
+	if b.Name == "" {
+		b.Name = fmt.Sprintf("%s (%s)", b.displayName(), role)
+	}
+	return b
+}
+
+func (config BotConfig) inherit(b BotConfig) BotConfig {
+	if b.MaxConcurrentUsers == 0 {
+		b.MaxConcurrentUsers = config.MaxConcurrentUsers
+	}
+	if b.MaxSessions == 0 {
+		b.MaxSessions = config.MaxSessions
+	}
+	if b.SolveWorkers == 0 {
+		b.SolveWorkers = config.SolveWorkers
+	}
+	if b.CoachWorkers == 0 {
+		b.CoachWorkers = config.CoachWorkers
+	}
+	if b.AdminWorkers == 0 {
+		b.AdminWorkers = config.AdminWorkers
+	}
+	if b.MaxQueue == 0 {
+		b.MaxQueue = config.MaxQueue
+	}
+	if b.SolveTimeout == 0 {
+		b.SolveTimeout = config.SolveTimeout
+	}
+	if b.CoachTimeout == 0 {
+		b.CoachTimeout = config.CoachTimeout
+	}
+	if b.QueueTimeout == 0 {
+		b.QueueTimeout = config.QueueTimeout
+	}
+	if b.BreakerThreshold == 0 {
+		b.BreakerThreshold = config.BreakerThreshold
+	}
+	if b.BreakerCooldown == 0 {
+		b.BreakerCooldown = config.BreakerCooldown
+	}
+	if b.Retry == (RetryConfig{}) {
+		b.Retry = config.Retry
+	}
+	if b.SolveShards == 0 {
+		b.SolveShards = config.SolveShards
+	}
+	if b.Cache == (CacheConfig{}) {
+		b.Cache = config.Cache
+	}
+	if b.SlowThreshold == 0 {
+		b.SlowThreshold = config.SlowThreshold
+	}
+	if b.SlowLog == "" {
+		b.SlowLog = config.SlowLog
+	}
+	if b.StallTimeout == 0 {
+		b.StallTimeout = config.StallTimeout
+	}
+	if b.RunAs == "" {
+		b.RunAs = config.RunAs
+	}
+	if !b.Sandbox.Enabled {
+		b.Sandbox = config.Sandbox
+	}
+	if b.Limits == (ProcessLimits{}) {
+		b.Limits = config.Limits
+	}
+	if !b.ArgsFromStdin {
+		b.ArgsFromStdin = config.ArgsFromStdin
+	}
+	if b.MaxOutput == nil {
+		b.MaxOutput = config.MaxOutput
+	}
+	return b
+}
+
+func NewFailoverEngine(config BotConfig) (*FailoverEngine, error) {
+	f := &FailoverEngine{
+		maxFailures: config.MaxFailures,
+		done:        make(chan struct{}),
+	}
+	if f.maxFailures <= 0 {
+		f.maxFailures = 3
+	}
+
+	for i := range config.Backends {
+		bc := config.backendConfig(i)
+		eng, err := newEngine(bc)
+		if err != nil {
+			slog.Warn("Skipping engine", "engine", bc.Name, "err", err)
+			continue
+		}
+		f.backends = append(f.backends, &backend{name: bc.Name, engine: eng, healthy: true})
+	}
+
+	if len(f.backends) == 0 {
+		return nil, errors.New("no usable engine backends")
+	}
+
+	interval := config.HealthInterval
+	if interval <= 0 {
+		interval = 10000
+	}
+	go f.healthCheck(time.Duration(interval) * time.Millisecond)
+
+	return f, nil
+}
+
+func (f *FailoverEngine) healthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+		}
+
+		for _, b := range f.unhealthy() {
+			_, err := b.engine.WordList(context.Background(), "all")
+			f.record(b, err == nil)
+		}
+	}
+}
+
+// unhealthy lists the backends that failed over, which healthCheck probes
+// until they answer again
+func (f *FailoverEngine) unhealthy() []*backend {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var down []*backend
+	for _, b := range f.backends {
+		if !b.healthy {
+			down = append(down, b)
+		}
+	}
+	return down
+}
+
+func (f *FailoverEngine) record(b *backend, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if ok {
+		if !b.healthy {
+			slog.Info("Engine is healthy again", "engine", b.name)
+		}
+		b.failures = 0
+		b.healthy = true
+		return
+	}
+
+	b.failures++
+	if b.healthy && b.failures >= f.maxFailures {
+		slog.Warn("Engine failing over", "engine", b.name, "failures", b.failures)
+		b.healthy = false
+	}
+}
+
+func (f *FailoverEngine) candidates() []*backend {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var healthy []*backend
+	for _, b := range f.backends {
+		if b.healthy {
+			healthy = append(healthy, b)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return f.backends
+	}
+	return healthy
+}
+
+// streamStartedError is the error of a backend that failed after turns of a
+// stream were sent, which the next backend can't take back
+type streamStartedError struct {
+	err error
+}
+
+func (err streamStartedError) Error() string {
+	return err.err.Error()
+}
+
+func (err streamStartedError) Unwrap() error {
+	return err.err
+}
+
+func failover[T any](ctx context.Context, f *FailoverEngine, call func(Engine) (T, error)) (result T, err error) {
+	for _, b := range f.candidates() {
+		result, err = call(b.engine)
+		var streamErr StreamError
+		if errors.Is(err, context.Canceled) || errors.As(err, &streamErr) {
+			// The client went away, not the engine
+			return
+		}
+		if requestFault(err) {
+			// Every backend would say the same
+			return
+		}
+		f.record(b, err == nil)
+		if err == nil {
+			return
+		}
+		slog.WarnContext(ctx, "Engine failed", "engine", b.name, "err", err)
+		if started, ok := err.(streamStartedError); ok {
+			return result, started.err
+		}
+	}
+	return
+}
+
+func (f *FailoverEngine) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	return failover(ctx, f, func(e Engine) ([]WordReport, error) {
+		return e.Solve(ctx, word)
+	})
+}
+
+func (f *FailoverEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	sent := false
+	_, err := failover(ctx, f, func(e Engine) (struct{}, error) {
+		err := e.SolveStream(ctx, word, func(turn int, report WordReport) error {
+			sent = true
+			return emit(turn, report)
+		})
+		if err != nil && sent {
+			err = streamStartedError{err}
+		}
+		return struct{}{}, err
+	})
+	return err
+}
+
+func (f *FailoverEngine) Coach(ctx context.Context, word string, guesses []string) (*WordReport, error) {
+	return failover(ctx, f, func(e Engine) (*WordReport, error) {
+		return e.Coach(ctx, word, guesses)
+	})
+}
+
+func (f *FailoverEngine) CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error) {
+	return failover(ctx, f, func(e Engine) (*WordReport, error) {
+		return e.CoachFeedback(ctx, feedback)
+	})
+}
+
+func (f *FailoverEngine) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	return failover(ctx, f, func(e Engine) (CoachSession, error) {
+		return e.CoachSession(ctx, word)
+	})
+}
+
+func (f *FailoverEngine) Suggest(ctx context.Context, c Constraints) (*Suggestion, error) {
+	return failover(ctx, f, func(e Engine) (*Suggestion, error) {
+		return e.Suggest(ctx, c)
+	})
+}
+
+func (f *FailoverEngine) WordList(ctx context.Context, list string) ([]string, error) {
+	return failover(ctx, f, func(e Engine) ([]string, error) {
+		return e.WordList(ctx, list)
+	})
+}
+
+func (f *FailoverEngine) Close() {
+	close(f.done)
+	for _, b := range f.backends {
+		b.engine.Close()
+	}
+}
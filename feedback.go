@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Feedback struct {
+	Word   string `json:"word"`
+	Colors string `json:"colors"`
+}
+
+func (f Feedback) String() string {
+	return f.Word + ":" + f.Colors
+}
+
+func colorsValid(word, colors string) bool {
+	return len(colors) == len([]rune(word)) && strings.Trim(colors, "gyb") == ""
+}
+
+func parseFeedback(s string) ([]Feedback, error) {
+	var feedback []Feedback
+	for _, g := range strings.Split(s, ",") {
+		word, colors, ok := strings.Cut(foldWord(g), ":")
+		if !ok || !wordValid(word) {
+			return nil, fmt.Errorf("invalid guess %q", g)
+		}
+
+		if !colorsValid(word, colors) {
+			return nil, fmt.Errorf("invalid colors for guess %q", g)
+		}
+
+		feedback = append(feedback, Feedback{Word: word, Colors: colors})
+	}
+	return feedback, nil
+}
+
+func feedbackArgs(feedback []Feedback) []string {
+	args := make([]string, len(feedback))
+	for i, f := range feedback {
+		args[i] = f.String()
+	}
+	return args
+}
+
+func coachFeedback(w http.ResponseWriter, r *http.Request, ip string, feedback []Feedback) {
+	guessesStr := strings.Join(feedbackArgs(feedback), ",")
+
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/coach", "ip", ip, "guess", guessesStr, "engine", name)
+	start := time.Now()
+
+	data, err := eng.CoachFeedback(requestContext(r), feedback)
+	router.record(name, err)
+	if err != nil {
+		internalError(w, r, err)
+	} else {
+		writeResponse(w, r, data)
+	}
+
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/coach", "duration", time.Since(start))
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type GameConfig struct {
+	MaxGuesses int `toml:"max_guesses"`
+	SessionTTL int `toml:"session_ttl" comment:"Seconds after which idle games are forgotten"`
+}
+
+type Game struct {
+	ID         string   `json:"id"`
+	HardMode   bool     `json:"hardMode"`
+	MaxGuesses int      `json:"maxGuesses"`
+	Guesses    []string `json:"guesses"`
+	Colors     []string `json:"colors"`
+	State      string   `json:"state"`
+	Answer     string   `json:"answer,omitempty"`
+	Dict       string   `json:"dict,omitempty"`
+	Length     int      `json:"length"`
+
+	target   string
+	lastSeen time.Time
+}
+
+type gameStore struct {
+	mu         sync.Mutex
+	games      map[string]*Game
+	maxGuesses int
+	ttl        time.Duration
+}
+
+var games *gameStore
+
+func newGameStore(config GameConfig) *gameStore {
+	s := &gameStore{
+		games:      make(map[string]*Game),
+		maxGuesses: config.MaxGuesses,
+		ttl:        time.Duration(config.SessionTTL) * time.Second,
+	}
+	if s.maxGuesses <= 0 {
+		s.maxGuesses = 6
+	}
+	if s.ttl <= 0 {
+		s.ttl = 24 * time.Hour
+	}
+
+	go s.expire()
+	return s
+}
+
+func (s *gameStore) expire() {
+	for range time.Tick(time.Minute) {
+		s.mu.Lock()
+		for id, g := range s.games {
+			if time.Since(g.lastSeen) > s.ttl {
+				delete(s.games, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *gameStore) create(target string, hard bool, dictionary string) Game {
+	g := &Game{
+		ID:         uuid.New().String(),
+		HardMode:   hard,
+		Dict:       dictionary,
+		Length:     utf8.RuneCountInString(target),
+		MaxGuesses: s.maxGuesses,
+		Guesses:    []string{},
+		Colors:     []string{},
+		State:      "playing",
+		target:     target,
+		lastSeen:   time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[g.ID] = g
+	return g.view()
+}
+
+func (s *gameStore) get(id string) (Game, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.games[id]
+	if !ok {
+		return Game{}, false
+	}
+	g.lastSeen = time.Now()
+	return g.view(), true
+}
+
+func (g *Game) view() Game {
+	v := *g
+	v.Guesses = append([]string{}, g.Guesses...)
+	v.Colors = append([]string{}, g.Colors...)
+	if g.State != "playing" {
+		v.Answer = g.target
+	}
+	return v
+}
+
+func (g *Game) hardModeError(guess string) error {
+	word := []rune(guess)
+	for i, prev := range g.Guesses {
+		p := []rune(prev)
+		for j, c := range g.Colors[i] {
+			switch {
+			case c == 'g' && word[j] != p[j]:
+				return fmt.Errorf("letter %d must be %c", j+1, p[j])
+			case c == 'y' && !strings.ContainsRune(guess, p[j]):
+				return fmt.Errorf("guess must contain %c", p[j])
+			}
+		}
+	}
+	return nil
+}
+
+func (s *gameStore) guess(id, guess string) (Game, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.games[id]
+	if !ok {
+		return Game{}, http.StatusNotFound, fmt.Errorf("no game with id %s", id)
+	}
+	g.lastSeen = time.Now()
+
+	if g.State != "playing" {
+		return Game{}, http.StatusConflict, fmt.Errorf("game is already %s", g.State)
+	}
+
+	if n := utf8.RuneCountInString(guess); n != g.Length {
+		return Game{}, http.StatusBadRequest, fmt.Errorf("guess must have %d letters, got %d", g.Length, n)
+	}
+	if g.HardMode {
+		if err := g.hardModeError(guess); err != nil {
+			return Game{}, http.StatusBadRequest, err
+		}
+	}
+
+	colors := wordColors(guess, g.target)
+	g.Guesses = append(g.Guesses, guess)
+	g.Colors = append(g.Colors, colors)
+
+	switch {
+	case guess == g.target:
+		g.State = "won"
+	case len(g.Guesses) >= g.MaxGuesses:
+		g.State = "lost"
+	}
+
+	return g.view(), http.StatusOK, nil
+}
+
+func newGame(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	hard := r.Form.Get("hard") == "true"
+
+	answers := ofLength(wordsFor(r.Context()).Answers, wordLength(r.Context()))
+	if len(answers) == 0 {
+		httpError(w, "No answers of that length", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/game/new", "ip", ip, "param", "len")
+		return
+	}
+	target := answers[rand.IntN(len(answers))]
+	g := games.create(target, hard, dictionaryFrom(r.Context()))
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/game/new", "ip", ip, "game", g.ID, "hard", hard)
+	writeJSON(w, r, g)
+}
+
+func getGame(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	g, ok := games.get(r.PathValue("id"))
+	if !ok {
+		httpError(w, "No such game", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, g)
+}
+
+func guessGame(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+
+	ip := getIP(r)
+	id := r.PathValue("id")
+
+	r.ParseForm()
+	guess := foldWord(r.Form.Get("g"))
+
+	// Guesses are checked against the dictionary the game was started with
+	words := dict.Load()
+	if g, ok := games.get(id); ok {
+		words = dictionaryWords(g.Dict)
+	}
+	if !wordValid(guess) || !words.Validate(guess).Guess {
+		httpError(w, "Invalid guess", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/game/{id}/guess", "ip", ip, "game", id, "param", "g")
+		return
+	}
+
+	g, status, err := games.guess(id, guess)
+	if err != nil {
+		httpError(w, err.Error(), status)
+		slog.InfoContext(r.Context(), "Rejected request", "endpoint", "/game/{id}/guess", "ip", ip, "game", id, "status", status, "err", err)
+		return
+	}
+
+	writeJSON(w, r, g)
+}
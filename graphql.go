@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	solve(word: String!): [WordReport!]!
+	coach(word: String!, guesses: [String!]!): WordReport!
+	suggest(green: String, yellow: String, gray: String): Suggestion!
+	score(target: String!, guess: String!): String!
+	validate(word: String!): Validation!
+	openers(n: Int): [Guess!]!
+}
+
+type Guess {
+	word: String!
+	score: Float!
+}
+
+type WordReport {
+	user: Guess!
+	best: [Guess!]!
+	optionsLeft: [String!]!
+	eliminated: Int!
+	colors: String!
+}
+
+type Suggestion {
+	best: [Guess!]!
+	optionsLeft: [String!]!
+}
+
+type Validation {
+	word: String!
+	guess: Boolean!
+	answer: Boolean!
+}
+`
+
+type queryResolver struct{}
+
+type guessResolver struct {
+	g Guess
+}
+
+type reportResolver struct {
+	r WordReport
+}
+
+type suggestionResolver struct {
+	s Suggestion
+}
+
+type validationResolver struct {
+	v Validation
+}
+
+func guessResolvers(guesses []Guess) []guessResolver {
+	result := make([]guessResolver, len(guesses))
+	for i, g := range guesses {
+		result[i] = guessResolver{g}
+	}
+	return result
+}
+
+func (g guessResolver) Word() string {
+	return g.g.Word
+}
+
+func (g guessResolver) Score() float64 {
+	return float64(g.g.Score)
+}
+
+func (r reportResolver) User() guessResolver {
+	return guessResolver{r.r.User}
+}
+
+func (r reportResolver) Best() []guessResolver {
+	return guessResolvers(r.r.Best)
+}
+
+func (r reportResolver) OptionsLeft() []string {
+	return r.r.OptionsLeft
+}
+
+func (r reportResolver) Eliminated() int32 {
+	return r.r.Eliminated
+}
+
+func (r reportResolver) Colors() string {
+	return r.r.Colors
+}
+
+func (s suggestionResolver) Best() []guessResolver {
+	return guessResolvers(s.s.Best)
+}
+
+func (s suggestionResolver) OptionsLeft() []string {
+	return s.s.OptionsLeft
+}
+
+func (v validationResolver) Word() string {
+	return v.v.Word
+}
+
+func (v validationResolver) Guess() bool {
+	return v.v.Guess
+}
+
+func (v validationResolver) Answer() bool {
+	return v.v.Answer
+}
+
+// graphqlError logs and reports a failed query the way grpcError does, and
+// only tells the client what went wrong if it's the request's fault or
+// temporary. Anything else could give away paths or backend addresses
+func graphqlError(ctx context.Context, err error) error {
+	id := requestIDFrom(ctx)
+	logFailure(ctx, err)
+	if reportable(err) {
+		reportError(ctx, nil, err, nil)
+	}
+	var stalled StalledError
+	var indexErr IndexError
+	switch {
+	case errors.As(err, &stalled), errors.As(err, &indexErr), requestFault(err):
+		return fmt.Errorf("%v (%v)", err, id)
+	}
+	switch err.(type) {
+	case TimeoutError, CircuitOpenError, QueueFullError:
+		return fmt.Errorf("%v (%v)", err, id)
+	}
+	return fmt.Errorf("internal error (%v)", id)
+}
+
+func (queryResolver) Solve(ctx context.Context, args struct{ Word string }) ([]reportResolver, error) {
+	if !wordValidFor(ctx, args.Word) {
+		return nil, errors.New("invalid word")
+	}
+
+	name, eng := router.pick(ctx)
+	reports, err := eng.Solve(ctx, args.Word)
+	router.record(name, err)
+	if err != nil {
+		return nil, graphqlError(ctx, err)
+	}
+
+	result := make([]reportResolver, len(reports))
+	for i, r := range reports {
+		result[i] = reportResolver{r}
+	}
+	return result, nil
+}
+
+func (queryResolver) Coach(ctx context.Context, args struct {
+	Word    string
+	Guesses []string
+}) (*reportResolver, error) {
+	if !wordValidFor(ctx, args.Word) {
+		return nil, errors.New("invalid target word")
+	}
+	if len(args.Guesses) == 0 {
+		return nil, errors.New("expected guess")
+	}
+	for _, g := range args.Guesses {
+		if !wordValid(g) || !sameLength(g, args.Word) {
+			return nil, errors.New("invalid word")
+		}
+	}
+
+	name, eng := router.pick(ctx)
+	report, err := eng.Coach(ctx, args.Word, args.Guesses)
+	router.record(name, err)
+	if err != nil {
+		return nil, graphqlError(ctx, err)
+	}
+	return &reportResolver{*report}, nil
+}
+
+func (queryResolver) Suggest(ctx context.Context, args struct {
+	Green  *string
+	Yellow *string
+	Gray   *string
+}) (*suggestionResolver, error) {
+	form := url.Values{}
+	for key, value := range map[string]*string{"green": args.Green, "yellow": args.Yellow, "gray": args.Gray} {
+		if value != nil {
+			form.Set(key, *value)
+		}
+	}
+
+	c, err := parseConstraints(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+
+	name, eng := router.pick(ctx)
+	s, err := eng.Suggest(ctx, c)
+	router.record(name, err)
+	if err != nil {
+		return nil, graphqlError(ctx, err)
+	}
+	return &suggestionResolver{*s}, nil
+}
+
+func (queryResolver) Score(ctx context.Context, args struct{ Target, Guess string }) (string, error) {
+	target, guess := foldWord(args.Target), foldWord(args.Guess)
+	if !wordValidFor(ctx, target) || !wordValid(guess) {
+		return "", errors.New("invalid word")
+	}
+	if !sameLength(guess, target) {
+		return "", errors.New("guess and target must have as many letters")
+	}
+	return wordColors(guess, target), nil
+}
+
+func (queryResolver) Validate(ctx context.Context, args struct{ Word string }) (validationResolver, error) {
+	if !wordValidFor(ctx, args.Word) {
+		return validationResolver{}, errors.New("invalid word")
+	}
+	return validationResolver{wordsFor(ctx).Validate(args.Word)}, nil
+}
+
+func (queryResolver) Openers(args struct{ N *int32 }) ([]guessResolver, error) {
+	guesses := openers.get()
+	if guesses == nil {
+		return nil, errors.New("openers not computed yet")
+	}
+
+	if args.N != nil && int(*args.N) < len(guesses) {
+		if *args.N < 1 {
+			return nil, errors.New("invalid n")
+		}
+		guesses = guesses[:*args.N]
+	}
+	return guessResolvers(guesses), nil
+}
+
+// graphqlRequest is the body accepted by relay.Handler
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+func newGraphqlHandler() http.Handler {
+	schema := graphql.MustParseSchema(graphqlSchema, &queryResolver{})
+	h := &relay.Handler{Schema: schema}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(requestContext(r)))
+	})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/antonijn/wbot-server/wbotpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type GrpcEngine struct {
+	config BotConfig
+	conn   *grpc.ClientConn
+	client wbotpb.EngineClient
+}
+
+func NewGrpcEngine(config BotConfig) (*GrpcEngine, error) {
+	conn, err := grpc.NewClient(config.GrpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GrpcEngine{
+		config: config,
+		conn:   conn,
+		client: wbotpb.NewEngineClient(conn),
+	}, nil
+}
+
+func (g *GrpcEngine) Close() {
+	g.conn.Close()
+}
+
+func (g *GrpcEngine) call(ctx context.Context, timeout int, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+
+	err := f(ctx)
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return TimeoutError("timeout")
+	case codes.Canceled:
+		return context.Canceled
+	}
+
+	return err
+}
+
+func guessFromProto(g *wbotpb.Guess) Guess {
+	return Guess{Word: g.GetWord(), Score: g.GetScore()}
+}
+
+func reportFromProto(r *wbotpb.WordReport) WordReport {
+	best := make([]Guess, len(r.GetBest()))
+	for i, g := range r.GetBest() {
+		best[i] = guessFromProto(g)
+	}
+
+	return WordReport{
+		User:        guessFromProto(r.GetUser()),
+		Best:        best,
+		OptionsLeft: r.GetOptionsLeft(),
+		Eliminated:  r.GetEliminated(),
+		Colors:      r.GetColors(),
+	}
+}
+
+func (g *GrpcEngine) Solve(ctx context.Context, word string) (result []WordReport, err error) {
+	err = g.call(ctx, g.config.SolveTimeout, func(ctx context.Context) error {
+		resp, err := g.client.Solve(ctx, &wbotpb.SolveRequest{Word: word})
+		if err != nil {
+			return err
+		}
+
+		for _, r := range resp.GetReports() {
+			result = append(result, reportFromProto(r))
+		}
+		return nil
+	})
+	return
+}
+
+func (g *GrpcEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	result, err := g.Solve(ctx, word)
+	if err != nil {
+		return err
+	}
+	return emitAll(result, emit)
+}
+
+func (g *GrpcEngine) Coach(ctx context.Context, word string, guesses []string) (result *WordReport, err error) {
+	err = g.call(ctx, g.config.CoachTimeout, func(ctx context.Context) error {
+		resp, err := g.client.Coach(ctx, &wbotpb.CoachRequest{Word: word, Guesses: guesses})
+		if err != nil {
+			return err
+		}
+
+		report := reportFromProto(resp)
+		result = &report
+		return nil
+	})
+	return
+}
+
+func (g *GrpcEngine) CoachFeedback(ctx context.Context, feedback []Feedback) (result *WordReport, err error) {
+	req := &wbotpb.CoachFeedbackRequest{}
+	for _, f := range feedback {
+		req.Feedback = append(req.Feedback, &wbotpb.Feedback{Word: f.Word, Colors: f.Colors})
+	}
+
+	err = g.call(ctx, g.config.CoachTimeout, func(ctx context.Context) error {
+		resp, err := g.client.CoachFeedback(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		report := reportFromProto(resp)
+		result = &report
+		return nil
+	})
+	return
+}
+
+func (g *GrpcEngine) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	return &replaySession{ctx: ctx, eng: g, word: word}, nil
+}
+
+func (g *GrpcEngine) Suggest(ctx context.Context, c Constraints) (result *Suggestion, err error) {
+	req := &wbotpb.SuggestRequest{Green: c.Green, Gray: c.Gray}
+	for _, y := range c.Yellow {
+		req.Yellow = append(req.Yellow, &wbotpb.YellowLetter{
+			Letter:   string(y.Letter),
+			Position: int32(y.Position),
+		})
+	}
+
+	err = g.call(ctx, g.config.CoachTimeout, func(ctx context.Context) error {
+		resp, err := g.client.Suggest(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		result = &Suggestion{OptionsLeft: resp.GetOptionsLeft()}
+		for _, g := range resp.GetBest() {
+			result.Best = append(result.Best, guessFromProto(g))
+		}
+		return nil
+	})
+	return
+}
+
+func (g *GrpcEngine) WordList(ctx context.Context, list string) (words []string, err error) {
+	err = g.call(ctx, 1000, func(ctx context.Context) error {
+		resp, err := g.client.WordList(ctx, &wbotpb.WordListRequest{List: list})
+		words = resp.GetWords()
+		return err
+	})
+	return
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/antonijn/wbot-server/wbotpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	wbotpb.UnimplementedEngineServer
+}
+
+func guessToProto(g Guess) *wbotpb.Guess {
+	return &wbotpb.Guess{Word: g.Word, Score: g.Score}
+}
+
+func guessesToProto(guesses []Guess) []*wbotpb.Guess {
+	result := make([]*wbotpb.Guess, len(guesses))
+	for i, g := range guesses {
+		result[i] = guessToProto(g)
+	}
+	return result
+}
+
+func reportToProto(r *WordReport) *wbotpb.WordReport {
+	return &wbotpb.WordReport{
+		User:        guessToProto(r.User),
+		Best:        guessesToProto(r.Best),
+		OptionsLeft: r.OptionsLeft,
+		Eliminated:  r.Eliminated,
+		Colors:      r.Colors,
+	}
+}
+
+func reportsToProto(reports []WordReport) *wbotpb.SolveResponse {
+	resp := &wbotpb.SolveResponse{}
+	for i := range reports {
+		resp.Reports = append(resp.Reports, reportToProto(&reports[i]))
+	}
+	return resp
+}
+
+func suggestionToProto(s *Suggestion) *wbotpb.Suggestion {
+	return &wbotpb.Suggestion{Best: guessesToProto(s.Best), OptionsLeft: s.OptionsLeft}
+}
+
+func grpcPeer(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+func grpcError(ctx context.Context, err error) error {
+	id := requestIDFrom(ctx)
+	logFailure(ctx, err)
+	if reportable(err) {
+		reportError(ctx, nil, err, nil)
+	}
+	var stalled StalledError
+	var indexErr IndexError
+	switch {
+	case errors.As(err, &stalled):
+		return status.Errorf(codes.Unavailable, "%v (%v)", stalled, id)
+	case errors.As(err, &indexErr):
+		return status.Errorf(codes.Unavailable, "%v (%v)", indexErr, id)
+	case requestFault(err):
+		return status.Errorf(codes.InvalidArgument, "%v (%v)", err, id)
+	}
+	switch err.(type) {
+	case TimeoutError, CircuitOpenError:
+		return status.Errorf(codes.Unavailable, "%v (%v)", err, id)
+	case QueueFullError:
+		return status.Errorf(codes.ResourceExhausted, "%v (%v)", err, id)
+	}
+	return status.Errorf(codes.Internal, "internal error (%v)", id)
+}
+
+func (grpcServer) Solve(ctx context.Context, req *wbotpb.SolveRequest) (*wbotpb.SolveResponse, error) {
+	word := req.GetWord()
+	if !wordValidFor(ctx, word) {
+		return nil, status.Error(codes.InvalidArgument, "invalid word")
+	}
+
+	name, eng := router.pick(ctx)
+	slog.InfoContext(ctx, "Request", "endpoint", "grpc Solve", "ip", grpcPeer(ctx), "word", word, "engine", name)
+
+	reports, err := eng.Solve(ctx, word)
+	router.record(name, err)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return reportsToProto(reports), nil
+}
+
+func (grpcServer) Coach(ctx context.Context, req *wbotpb.CoachRequest) (*wbotpb.WordReport, error) {
+	word, guesses := req.GetWord(), req.GetGuesses()
+	if !wordValidFor(ctx, word) {
+		return nil, status.Error(codes.InvalidArgument, "invalid target word")
+	}
+	if len(guesses) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "expected guess")
+	}
+	for _, g := range guesses {
+		if !wordValid(g) || !sameLength(g, word) {
+			return nil, status.Error(codes.InvalidArgument, "invalid word")
+		}
+	}
+
+	name, eng := router.pick(ctx)
+	slog.InfoContext(ctx, "Request", "endpoint", "grpc Coach", "ip", grpcPeer(ctx), "word", word, "guess", strings.Join(guesses, ","), "engine", name)
+
+	report, err := eng.Coach(ctx, word, guesses)
+	router.record(name, err)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return reportToProto(report), nil
+}
+
+func (grpcServer) CoachFeedback(ctx context.Context, req *wbotpb.CoachFeedbackRequest) (*wbotpb.WordReport, error) {
+	var feedback []Feedback
+	for _, f := range req.GetFeedback() {
+		if !wordValidFor(ctx, f.GetWord()) || !colorsValid(f.GetWord(), f.GetColors()) || !sameLength(f.GetWord(), req.GetFeedback()[0].GetWord()) {
+			return nil, status.Error(codes.InvalidArgument, "invalid guess")
+		}
+		feedback = append(feedback, Feedback{Word: f.GetWord(), Colors: f.GetColors()})
+	}
+	if len(feedback) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "expected guess")
+	}
+
+	name, eng := router.pick(ctx)
+	slog.InfoContext(ctx, "Request", "endpoint", "grpc CoachFeedback", "ip", grpcPeer(ctx), "guess", strings.Join(feedbackArgs(feedback), ","), "engine", name)
+
+	report, err := eng.CoachFeedback(ctx, feedback)
+	router.record(name, err)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return reportToProto(report), nil
+}
+
+func (grpcServer) Suggest(ctx context.Context, req *wbotpb.SuggestRequest) (*wbotpb.Suggestion, error) {
+	form := map[string][]string{
+		"green": {req.GetGreen()},
+		"gray":  {req.GetGray()},
+	}
+	var yellow []string
+	for _, y := range req.GetYellow() {
+		yellow = append(yellow, fmt.Sprintf("%s:%d", y.GetLetter(), y.GetPosition()))
+	}
+	form["yellow"] = []string{strings.Join(yellow, ",")}
+
+	c, err := parseConstraints(ctx, form)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	name, eng := router.pick(ctx)
+	slog.InfoContext(ctx, "Request", "endpoint", "grpc Suggest", "ip", grpcPeer(ctx), "green", c.Green, "yellow", c.yellowString(), "gray", c.Gray, "engine", name)
+
+	s, err := eng.Suggest(ctx, c)
+	router.record(name, err)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return suggestionToProto(s), nil
+}
+
+func (grpcServer) WordList(ctx context.Context, req *wbotpb.WordListRequest) (*wbotpb.WordListResponse, error) {
+	switch req.GetList() {
+	case "", "all":
+		return &wbotpb.WordListResponse{Words: wordsFor(ctx).Words}, nil
+	case "answers":
+		return &wbotpb.WordListResponse{Words: wordsFor(ctx).Answers}, nil
+	}
+	return nil, status.Error(codes.InvalidArgument, "invalid list")
+}
+
+// grpcRoutes maps methods to the HTTP routes whose priority they share
+var grpcRoutes = map[string]string{
+	"Solve":         "/solve",
+	"Coach":         "/coach",
+	"CoachFeedback": "/coach",
+	"Suggest":       "/suggest",
+}
+
+func serveGrpc(config ServerConfig, tlsConfig *tls.Config) error {
+	auth, err := newAuthenticator(config.Auth)
+	if err != nil {
+		return err
+	}
+
+	lis := activatedListener("grpc")
+	if lis == nil {
+		if lis, err = net.Listen("tcp", fmt.Sprintf(":%d", config.GrpcPort)); err != nil {
+			return err
+		}
+	}
+	if config.ProxyProtocol {
+		lis = acceptProxyProtocol(lis)
+	}
+
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	opts = append(opts, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = grpcRequestID(ctx)
+		if err := grpcLimitIP(ctx); err != nil {
+			return nil, err
+		}
+		ctx, err := auth.grpc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := keyQuotas.grpc(ctx); err != nil {
+			return nil, err
+		}
+		if ctx, err = grpcDictionary(ctx); err != nil {
+			return nil, err
+		}
+
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		ctx = withPriority(ctx, routePriority(config.Priority, grpcRoutes[method]))
+		ctx = withClient(ctx, clientHost(grpcPeer(ctx)))
+		return handler(ctx, req)
+	}))
+	s := grpc.NewServer(opts...)
+	wbotpb.RegisterEngineServer(s, grpcServer{})
+	servers.Lock()
+	servers.grpc = append(servers.grpc, s)
+	servers.Unlock()
+
+	slog.Info("Serving gRPC", "addr", lis.Addr())
+	return s.Serve(lis)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout is how long requests in progress have to finish once the
+// server shuts down
+const shutdownTimeout = 30 * time.Second
+
+// ready is set once the server has started, having loaded its config and
+// validated its engines, and cleared when it starts shutting down
+var ready atomic.Bool
+
+// servers are the servers to shut down gracefully
+var servers struct {
+	sync.Mutex
+	http  []*http.Server
+	http3 []*http3.Server
+	grpc  []*grpc.Server
+}
+
+// mustServe runs serve, and exits if it fails. Servers that were shut down
+// return nil or http.ErrServerClosed, which isn't a failure
+func mustServe(serve func() error) {
+	if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal(err)
+	}
+}
+
+type ProbeStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// registerProbes adds /livez and /readyz to mux. They aren't versioned or
+// authenticated, as Kubernetes and load balancers probe them
+func registerProbes(mux *http.ServeMux) {
+	mux.HandleFunc("/livez", serveLivez)
+	mux.HandleFunc("/readyz", serveReadyz)
+}
+
+// serveLivez answers as long as the server can handle requests at all, so a
+// deadlocked or wedged server is restarted. A broken engine doesn't make it
+// fail, as restarting wouldn't fix that
+func serveLivez(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, ProbeStatus{Status: "ok"})
+}
+
+// serveReadyz fails while the server starts up or shuts down, or can't serve
+// requests, so traffic goes to other replicas
+func serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	checks := readinessChecks()
+	status := ProbeStatus{Status: "ok", Checks: make(map[string]string, len(checks))}
+	for name, err := range checks {
+		if err == "" {
+			status.Checks[name] = "ok"
+		} else {
+			status.Checks[name] = err
+			status.Status = "unavailable"
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Status != "ok" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, r, status)
+}
+
+// readinessChecks has the reason each check fails, or "" if it passes
+func readinessChecks() map[string]string {
+	checks := map[string]string{
+		"started": "",
+		"words":   "",
+		"engine":  engineUnusable(),
+		"queues":  "",
+	}
+	if !ready.Load() {
+		checks["started"] = "starting up or shutting down"
+	}
+	if d := dict.Load(); d == nil || len(d.Words) == 0 {
+		checks["words"] = "no word list"
+	}
+	for name, q := range queueStatuses() {
+		if q.Limit > 0 && q.Depth >= q.Limit {
+			checks["queues"] = name + " queue full"
+		}
+	}
+	return checks
+}
+
+// engineUnusable explains why no local engine can serve requests, if none
+// can: their circuit breakers are open, or their executables were replaced
+// with ones that don't work
+func engineUnusable() string {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	reason := ""
+	for name, b := range bots {
+		switch {
+		case b.breaker.status().State == "open":
+			reason = name + ": circuit breaker open"
+		case b.unusable.Load() != nil:
+			reason = name + ": " + *b.unusable.Load()
+		default:
+			return ""
+		}
+	}
+	return reason
+}
+
+// handleShutdown shuts down gracefully on SIGTERM or SIGINT. Readiness fails
+// first, and only after delay do the listeners close, so load balancers have
+// stopped sending new requests by then. Requests in progress, gRPC calls
+// included, get shutdownTimeout to finish. It returns once they are done, for
+// main to return
+func handleShutdown(delay time.Duration) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-sigs
+
+	slog.Info("Shutting down", "signal", sig, "delay", delay)
+	ready.Store(false)
+	sdNotify("STOPPING=1")
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	servers.Lock()
+	var wg sync.WaitGroup
+	for _, srv := range servers.http {
+		wg.Go(func() {
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("Requests still in progress at shutdown", "addr", srv.Addr, "err", err)
+			}
+		})
+	}
+	for _, srv := range servers.http3 {
+		wg.Go(func() {
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("Requests still in progress at shutdown", "addr", srv.Addr, "err", err)
+			}
+		})
+	}
+	for _, srv := range servers.grpc {
+		wg.Go(func() { stopGrpc(ctx, srv) })
+	}
+	servers.Unlock()
+	wg.Wait()
+	slog.Info("Shut down")
+}
+
+// stopGrpc lets the calls in progress on srv finish, or cancels them once ctx
+// is done
+func stopGrpc(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("gRPC calls still in progress at shutdown", "err", ctx.Err())
+		srv.Stop()
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// serveHTTP3 serves handler over HTTP/3 on the UDP address addr, for clients
+// on lossy networks, where QUIC recovers from lost packets without holding up
+// every request on the connection. It returns the handler for the TCP
+// listener, which advertises the QUIC one with Alt-Svc
+func serveHTTP3(addr string, handler http.Handler, tlsConfig *tls.Config, config HTTPConfig) http.Handler {
+	srv := &http3.Server{
+		Addr:           addr,
+		Handler:        handler,
+		TLSConfig:      tlsConfig,
+		IdleTimeout:    time.Duration(config.IdleTimeout) * time.Millisecond,
+		MaxHeaderBytes: config.MaxHeaderBytes,
+	}
+	servers.Lock()
+	servers.http3 = append(servers.http3, srv)
+	servers.Unlock()
+	go mustServe(func() error {
+		slog.Info("Serving HTTP/3", "addr", addr)
+		return srv.ListenAndServe()
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Until the QUIC listener is up, there's nothing to advertise
+		srv.SetQUICHeaders(w.Header())
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Defaults for HTTPConfig, which keep clients that trickle in headers or
+// leave connections idle from holding on to them forever
+const (
+	defaultReadHeaderTimeout = 10000
+	defaultIdleTimeout       = 120000
+)
+
+// HTTPConfig limits the HTTP connections clients make, with timeouts in
+// milliseconds. Zero read and write timeouts mean none
+type HTTPConfig struct {
+	ReadHeaderTimeout int  `toml:"read_header_timeout" comment:"Milliseconds a client may take to send the request headers"`
+	ReadTimeout       int  `toml:"read_timeout" comment:"Milliseconds a client may take to send the whole request; unlimited if 0"`
+	WriteTimeout      int  `toml:"write_timeout" comment:"Milliseconds from the end of the request headers until the response must be\nwritten; unlimited if 0. Streamed responses and WebSockets are exempt"`
+	IdleTimeout       int  `toml:"idle_timeout" comment:"Milliseconds a keep-alive connection may wait for the next request"`
+	MaxHeaderBytes    int  `toml:"max_header_bytes" comment:"Size limit of the request headers; 1 MiB if 0"`
+	H2C               bool `toml:"h2c" comment:"Also accept HTTP/2 without TLS (h2c with prior knowledge) on listeners\nthat don't use TLS"`
+}
+
+func newHTTPServer(addr string, handler http.Handler, tlsConfig *tls.Config, config HTTPConfig) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: time.Duration(config.ReadHeaderTimeout) * time.Millisecond,
+		ReadTimeout:       time.Duration(config.ReadTimeout) * time.Millisecond,
+		WriteTimeout:      time.Duration(config.WriteTimeout) * time.Millisecond,
+		IdleTimeout:       time.Duration(config.IdleTimeout) * time.Millisecond,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	// Load balancers and gRPC-style clients multiplex requests over HTTP/2
+	// connections, which they'd otherwise need TLS for
+	if tlsConfig == nil && config.H2C {
+		srv.Protocols = new(http.Protocols)
+		srv.Protocols.SetHTTP1(true)
+		srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return srv
+}
+
+// keepOpen lifts the read and write timeouts of a response that is streamed
+// or upgraded to a WebSocket, which may well outlast them
+func keepOpen(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+type JobConfig struct {
+	Concurrency int    `toml:"concurrency" comment:"Number of jobs solved at once"`
+	MaxPending  int    `toml:"max_pending" comment:"Jobs are rejected with 429 while this many are queued or running"`
+	Retention   int    `toml:"retention" comment:"Seconds that finished jobs are kept"`
+	Path        string `toml:"path" comment:"Keep jobs in this database file, so they survive restarts; only kept in\nmemory if unset"`
+}
+
+// Job is a solve run in the background, for clients that can't wait on a
+// single request
+type Job struct {
+	ID       string       `json:"id"`
+	Word     string       `json:"word"`
+	State    string       `json:"state"`
+	Result   []WordReport `json:"result,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	Created  time.Time    `json:"created"`
+	Finished *time.Time   `json:"finished,omitempty"`
+	Dict     string       `json:"dict,omitempty"`
+
+	client   string
+	priority priority
+}
+
+type jobStore struct {
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	slots      chan struct{}
+	pending    int
+	maxPending int
+	retention  time.Duration
+	db         *bolt.DB
+}
+
+var jobs *jobStore
+
+func newJobStore(config JobConfig) (*jobStore, error) {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	s := &jobStore{
+		jobs:       make(map[string]*Job),
+		slots:      make(chan struct{}, concurrency),
+		maxPending: config.MaxPending,
+		retention:  time.Duration(config.Retention) * time.Second,
+	}
+	if s.maxPending <= 0 {
+		s.maxPending = 1000
+	}
+	if s.retention <= 0 {
+		s.retention = time.Hour
+	}
+
+	if config.Path != "" {
+		if err := s.open(config.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	go s.expire()
+	return s, nil
+}
+
+func (s *jobStore) expire() {
+	for range time.Tick(time.Minute) {
+		s.mu.Lock()
+		for id, j := range s.jobs {
+			if j.Finished != nil && time.Since(*j.Finished) > s.retention {
+				delete(s.jobs, id)
+				s.forget(id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// submit queues a solve of word, which runs with the client, priority and
+// dictionary of ctx but isn't cancelled with it
+func (s *jobStore) submit(ctx context.Context, word string) (Job, error) {
+	j := &Job{
+		ID:       uuid.New().String(),
+		Word:     foldWord(word),
+		State:    "queued",
+		Created:  time.Now().UTC(),
+		client:   clientFrom(ctx),
+		Dict:     dictionaryFrom(ctx),
+		priority: priorityFrom(ctx),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending >= s.maxPending {
+		return Job{}, QueueFullError{RetryAfter: time.Minute}
+	}
+	s.pending++
+	s.jobs[j.ID] = j
+	s.save(j)
+
+	go s.run(j)
+	return *j, nil
+}
+
+func (s *jobStore) run(j *Job) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	s.mu.Lock()
+	j.State = "running"
+	s.save(j)
+	s.mu.Unlock()
+
+	ctx := withDictionary(withPriority(withClient(context.Background(), j.client), j.priority), j.Dict)
+	start := time.Now()
+	var reports []WordReport
+	var err error
+	// A stored job's dictionary may have been removed from the config since
+	if _, ok := dictionaries[j.Dict]; j.Dict != "" && !ok {
+		err = fmt.Errorf("unknown dictionary %q", j.Dict)
+	} else {
+		name, eng := router.pick(ctx)
+		reports, err = eng.Solve(ctx, j.Word)
+		router.record(name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.save(j)
+
+	finished := time.Now().UTC()
+	j.Finished = &finished
+	s.pending--
+	if err != nil {
+		slog.Warn("Job failed", "job", j.ID, "word", j.Word, "err", err)
+		j.State = "failed"
+		j.Error = err.Error()
+		return
+	}
+
+	slog.Info("Job done", "job", j.ID, "word", j.Word, "duration", time.Since(start))
+	j.State = "done"
+	j.Result = reports
+}
+
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+func submitSolveJob(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	req, err := parseSolveRequest(r)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/jobs/solve", "ip", ip, "err", err)
+		return
+	}
+
+	j, err := jobs.submit(requestContext(r), req.Word)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/jobs/solve", "ip", ip, "word", req.Word, "job", j.ID)
+	w.Header().Set("Location", "/v"+apiVersion+"/jobs/"+j.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(j)
+}
+
+func getJob(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	j, ok := jobs.get(r.PathValue("id"))
+	if !ok {
+		httpError(w, "No such job", http.StatusNotFound)
+		return
+	}
+
+	setDefaultCacheControl(w, "no-store")
+	writeJSON(w, r, j)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var jobsBucket = []byte("jobs")
+
+// jobRecord is how jobs are stored, along with what they need to be run
+// again after a restart
+type jobRecord struct {
+	Job
+	Client   string   `json:"client"`
+	Priority priority `json:"priority"`
+}
+
+// open loads the jobs stored at path, and dispatches those that hadn't
+// finished again
+func (s *jobStore) open(path string) error {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+
+	var unfinished []*Job
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(jobsBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var rec jobRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				slog.Warn("Ignoring stored job", "job", string(k), "err", err)
+				return nil
+			}
+
+			j := rec.Job
+			j.client, j.priority = rec.Client, rec.Priority
+			if j.Finished == nil {
+				j.State = "queued"
+				unfinished = append(unfinished, &j)
+			}
+			s.jobs[j.ID] = &j
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	s.db = db
+	s.pending = len(unfinished)
+	slog.Info("Loaded jobs", "jobs", len(s.jobs), "unfinished", len(unfinished))
+	for _, j := range unfinished {
+		go s.run(j)
+	}
+	return nil
+}
+
+// save stores j; s.mu must be held
+func (s *jobStore) save(j *Job) {
+	if s.db == nil {
+		return
+	}
+
+	data, err := json.Marshal(jobRecord{Job: *j, Client: j.client, Priority: j.priority})
+	if err == nil {
+		err = s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(jobsBucket).Put([]byte(j.ID), data)
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to store job", "job", j.ID, "err", err)
+	}
+}
+
+func (s *jobStore) forget(id string) {
+	if s.db == nil {
+		return
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		slog.Error("Failed to delete job", "job", id, "err", err)
+	}
+}
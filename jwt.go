@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTConfig struct {
+	Issuer   string `toml:"issuer" comment:"Tokens must match issuer and audience if set"`
+	JWKSURL  string `toml:"jwks_url"`
+	Audience string `toml:"audience"`
+}
+
+// jwtValidator checks tokens issued by an identity provider against the keys
+// it publishes, which are refreshed in the background
+type jwtValidator struct {
+	keys   keyfunc.Keyfunc
+	parser *jwt.Parser
+}
+
+func newJWTValidator(config JWTConfig) (*jwtValidator, error) {
+	if config.JWKSURL == "" {
+		return nil, nil
+	}
+
+	keys, err := keyfunc.NewDefault([]string{config.JWKSURL})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+	return &jwtValidator{keys: keys, parser: jwt.NewParser(opts...)}, nil
+}
+
+// subject validates token and returns its subject claim
+func (v *jwtValidator) subject(token string) (string, error) {
+	parsed, err := v.parser.Parse(token, v.keys.Keyfunc)
+	if err != nil {
+		return "", err
+	}
+
+	sub, err := parsed.Claims.GetSubject()
+	if err == nil && sub == "" {
+		err = errors.New("token has no subject")
+	}
+	return sub, err
+}
+
+type subjectKey struct{}
+
+func withSubject(ctx context.Context, sub string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, sub)
+}
+
+// subjectFrom is the subject of the JWT the request was made with
+func subjectFrom(ctx context.Context) string {
+	sub, _ := ctx.Value(subjectKey{}).(string)
+	return sub
+}
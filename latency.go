@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Percentiles are over the most recent latencyWindow observations
+const latencyWindow = 1024
+
+type LatencyStats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+type LatencyStatus struct {
+	Endpoints map[string]LatencyStats `json:"endpoints"`
+	QueueWait map[string]LatencyStats `json:"queueWait"`
+	Exec      map[string]LatencyStats `json:"exec"`
+}
+
+type latencyRing struct {
+	samples []time.Duration
+	next    int
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	if len(r.samples) < latencyWindow {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencyWindow
+}
+
+// stats gives the percentiles in milliseconds
+func (r *latencyRing) stats() LatencyStats {
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+
+	at := func(p float64) float64 {
+		i := min(len(sorted)-1, int(p*float64(len(sorted))))
+		return float64(sorted[i]) / float64(time.Millisecond)
+	}
+	return LatencyStats{Count: len(sorted), P50: at(0.5), P90: at(0.9), P99: at(0.99)}
+}
+
+type latencyTracker struct {
+	mu    sync.Mutex
+	rings map[string]*latencyRing
+}
+
+func (l *latencyTracker) observe(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rings == nil {
+		l.rings = map[string]*latencyRing{}
+	}
+	r, ok := l.rings[key]
+	if !ok {
+		r = &latencyRing{}
+		l.rings[key] = r
+	}
+	r.add(d)
+}
+
+func (l *latencyTracker) stats() map[string]LatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]LatencyStats, len(l.rings))
+	for key, r := range l.rings {
+		stats[key] = r.stats()
+	}
+	return stats
+}
+
+var endpointLatency, queueLatency, execLatency latencyTracker
+
+// engineOp names an engine operation as engine/op, like the queue statuses
+func engineOp(engine string, args []string) string {
+	if len(args) == 0 {
+		return engine
+	}
+	return engine + "/" + args[0]
+}
+
+func timed(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		h.ServeHTTP(w, r)
+		endpointLatency.observe(route, time.Since(start))
+	})
+}
+
+func latencyStatus() LatencyStatus {
+	return LatencyStatus{
+		Endpoints: endpointLatency.stats(),
+		QueueWait: queueLatency.stats(),
+		Exec:      execLatency.stats(),
+	}
+}
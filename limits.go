@@ -0,0 +1,11 @@
+package main
+
+// ProcessLimits caps the memory and CPU of each engine process. With Cgroup
+// set to a cgroup v2 directory the server may create children in, every
+// process gets a cgroup of its own under it; otherwise memory is limited
+// with RLIMIT_AS, and CPUs can't be limited
+type ProcessLimits struct {
+	MemoryMB int     `toml:"memory_mb"`
+	CPUs     float64 `toml:"cpus" comment:"Needs cgroup"`
+	Cgroup   string  `toml:"cgroup" comment:"cgroup v2 directory the server may write to, with the memory and cpu\ncontrollers enabled; memory is limited with RLIMIT_AS if unset"`
+}
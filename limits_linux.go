@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupSweepInterval is how often the cgroups of exited engine processes
+// are removed
+const cgroupSweepInterval = 30 * time.Second
+
+const cgroupPrefix = "wbot-engine-"
+
+var cgroupSeq atomic.Uint64
+
+type processLimiter struct {
+	limits ProcessLimits
+}
+
+func newProcessLimiter(limits ProcessLimits) (*processLimiter, error) {
+	if limits == (ProcessLimits{}) {
+		return nil, nil
+	}
+	if limits.Cgroup == "" {
+		if limits.CPUs > 0 {
+			return nil, errors.New("limits.cpus requires limits.cgroup")
+		}
+		return &processLimiter{limits: limits}, nil
+	}
+
+	controllers, err := os.ReadFile(filepath.Join(limits.Cgroup, "cgroup.subtree_control"))
+	if err != nil {
+		return nil, fmt.Errorf("limits.cgroup: %w", err)
+	}
+	for _, c := range []string{"memory", "cpu"} {
+		if !strings.Contains(" "+strings.TrimSpace(string(controllers))+" ", " "+c+" ") {
+			return nil, fmt.Errorf("limits.cgroup: %s controller not enabled in %s/cgroup.subtree_control", c, limits.Cgroup)
+		}
+	}
+
+	l := &processLimiter{limits: limits}
+	go l.sweep()
+	return l, nil
+}
+
+// sweep removes the cgroups of engine processes that have exited. Removing a
+// cgroup that still has processes fails, and young ones are left alone in
+// case their process hasn't been started yet
+func (l *processLimiter) sweep() {
+	for range time.Tick(cgroupSweepInterval) {
+		entries, err := os.ReadDir(l.limits.Cgroup)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || !e.IsDir() || !strings.HasPrefix(e.Name(), cgroupPrefix) || time.Since(info.ModTime()) < cgroupSweepInterval {
+				continue
+			}
+			os.Remove(filepath.Join(l.limits.Cgroup, e.Name()))
+		}
+	}
+}
+
+// prepare makes cmd start in a cgroup of its own
+func (l *processLimiter) prepare(cmd *exec.Cmd) error {
+	if l == nil || l.limits.Cgroup == "" {
+		return nil
+	}
+
+	dir := filepath.Join(l.limits.Cgroup, fmt.Sprintf("%s%d-%d", cgroupPrefix, os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return err
+	}
+
+	settings := map[string]string{}
+	if l.limits.MemoryMB > 0 {
+		settings["memory.max"] = fmt.Sprint(l.limits.MemoryMB << 20)
+		settings["memory.swap.max"] = "0"
+	}
+	if l.limits.CPUs > 0 {
+		const period = 100000
+		settings["cpu.max"] = fmt.Sprintf("%d %d", int(l.limits.CPUs*period), period)
+	}
+	for name, value := range settings {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0)
+		// Without swap accounting there is no memory.swap.max
+		if err != nil && !(name == "memory.swap.max" && errors.Is(err, os.ErrNotExist)) {
+			os.Remove(dir)
+			return err
+		}
+	}
+
+	fd, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		os.Remove(dir)
+		return err
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+	return nil
+}
+
+// started applies the limits that can only be set once cmd is running, and
+// releases what prepare held on to
+func (l *processLimiter) started(cmd *exec.Cmd) {
+	if l == nil {
+		return
+	}
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.UseCgroupFD {
+		unix.Close(cmd.SysProcAttr.CgroupFD)
+		return
+	}
+
+	if cmd.Process != nil && l.limits.MemoryMB > 0 {
+		limit := uint64(l.limits.MemoryMB) << 20
+		unix.Prlimit(cmd.Process.Pid, unix.RLIMIT_AS, &unix.Rlimit{Cur: limit, Max: limit}, nil)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+)
+
+type processLimiter struct{}
+
+func newProcessLimiter(limits ProcessLimits) (*processLimiter, error) {
+	if limits != (ProcessLimits{}) {
+		return nil, errors.New("engine process limits are only supported on Linux")
+	}
+	return nil, nil
+}
+
+func (l *processLimiter) prepare(cmd *exec.Cmd) error { return nil }
+
+func (l *processLimiter) started(cmd *exec.Cmd) {}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+const defaultSocketMode = 0o660
+
+// ListenerConfig is one of several listeners serving the API, each with its
+// own choice of endpoints and whether TLS and authentication are used
+type ListenerConfig struct {
+	Name          string `toml:"name" comment:"Use the socket systemd passed with this FileDescriptorName= instead of\nlisten, if there is one"`
+	Listen        string `toml:"listen" comment:"Address to listen on, \"host:port\" or \"unix:path\""`
+	SocketMode    string `toml:"socket_mode" comment:"Permissions of the Unix socket, in octal; 0660 by default"`
+	SocketOwner   string `toml:"socket_owner" comment:"Owner of the Unix socket, \"user\", \"user:group\" or \":group\""`
+	Endpoints     string `toml:"endpoints" comment:"Endpoints to serve: all (the default), api for all but the admin\nendpoints, or admin for only those"`
+	Plaintext     bool   `toml:"plaintext" comment:"Serve plain HTTP even if TLS is configured"`
+	Anonymous     bool   `toml:"anonymous" comment:"Serve the API without requiring an API key or token from [server.auth]"`
+	HTTP3Addr     string `toml:"http3_addr" comment:"Also serve HTTP/3 on this UDP address, advertised with Alt-Svc; needs TLS"`
+	ProxyProtocol bool   `toml:"proxy_protocol" comment:"Read the client address from a PROXY protocol header on each connection"`
+}
+
+// listenerConfigs are the configured listeners, or else the single listener
+// that listen, or port, describes
+func (config ServerConfig) listenerConfigs() []ListenerConfig {
+	if len(config.Listeners) > 0 {
+		return config.Listeners
+	}
+	addr := config.Listen
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", config.Port)
+	}
+	return []ListenerConfig{{
+		Listen:        addr,
+		SocketMode:    config.SocketMode,
+		SocketOwner:   config.SocketOwner,
+		HTTP3Addr:     config.HTTP3Addr,
+		ProxyProtocol: config.ProxyProtocol,
+	}}
+}
+
+func (config ListenerConfig) serves(endpoints string) bool {
+	return config.Endpoints == "" || config.Endpoints == "all" || config.Endpoints == endpoints
+}
+
+func (config ListenerConfig) String() string {
+	if config.Listen == "" {
+		return config.Name
+	}
+	return config.Listen
+}
+
+type apiListener struct {
+	net.Listener
+	config ListenerConfig
+}
+
+// apiListeners opens the configured listeners, using sockets passed by
+// systemd where their names match. Passed sockets that no listener claims
+// serve everything with the default settings, and replace listen and port if
+// [[server.listeners]] isn't used
+func apiListeners(config ServerConfig) ([]apiListener, error) {
+	claimed := make(map[string]bool)
+	var listeners []apiListener
+	closeAll := func() {
+		for _, l := range listeners {
+			if _, ok := activatedNames[l.Listener]; !ok {
+				l.Close()
+			}
+		}
+	}
+
+	for _, lc := range config.listenerConfigs() {
+		if len(config.Listeners) == 0 && len(activated["api"]) > 0 {
+			break
+		}
+
+		found := false
+		if lc.Name != "" {
+			for _, l := range activated["api"] {
+				if activatedNames[l] == lc.Name {
+					listeners = append(listeners, apiListener{l, lc})
+					found = true
+				}
+			}
+			claimed[lc.Name] = true
+		}
+		if found {
+			continue
+		}
+		if lc.Listen == "" {
+			closeAll()
+			return nil, fmt.Errorf("listener %q: systemd passed no socket by that name", lc.Name)
+		}
+		l, err := listen(lc)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, apiListener{l, lc})
+	}
+
+	for _, l := range activated["api"] {
+		if !claimed[activatedNames[l]] {
+			lc := ListenerConfig{Name: activatedNames[l]}
+			if len(config.Listeners) == 0 {
+				lc.ProxyProtocol = config.ProxyProtocol
+			}
+			listeners = append(listeners, apiListener{l, lc})
+		}
+	}
+
+	for i, l := range listeners {
+		if l.config.ProxyProtocol {
+			listeners[i].Listener = acceptProxyProtocol(l.Listener)
+		}
+	}
+	return listeners, nil
+}
+
+// onListener serves h, the shared middleware, with mux as the handler for the
+// listener's requests
+func onListener(h http.Handler, mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), listenerMuxKey{}, mux)))
+	})
+}
+
+type listenerMuxKey struct{}
+
+// listenerMux hands requests to the mux of the listener they came in on
+var listenerMux = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux, ok := r.Context().Value(listenerMuxKey{}).(http.Handler)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	mux.ServeHTTP(w, r)
+})
+
+// listen opens a listener on config.Listen, which is "host:port" for TCP or
+// "unix:path" for a Unix socket
+func listen(config ListenerConfig) (net.Listener, error) {
+	addr := config.Listen
+
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	// A socket left behind by a server that didn't shut down cleanly would
+	// keep us from binding
+	if info, err := os.Lstat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	mode, _ := socketMode(config.SocketMode)
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if config.SocketOwner != "" {
+		if err := chownSocket(path, config.SocketOwner); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	slog.Info("Listening on Unix socket", "path", path, "mode", fmt.Sprintf("%#o", mode), "owner", config.SocketOwner)
+	return l, nil
+}
+
+func socketMode(s string) (fs.FileMode, error) {
+	if s == "" {
+		return defaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("socket_mode must be octal permissions such as 0660, got %q", s)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// chownSocket gives the socket at path to owner, "user", "user:group" or
+// ":group"
+func chownSocket(path, owner string) error {
+	name, group, _ := strings.Cut(owner, ":")
+	uid, gid := -1, -1
+	if name != "" {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return err
+		}
+	}
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return err
+		}
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// viaUnixSocket reports whether r came in over a Unix socket, whose peers are
+// local processes let in by the socket's permissions
+func viaUnixSocket(r *http.Request) bool {
+	_, ok := r.Context().Value(http.LocalAddrContextKey).(*net.UnixAddr)
+	return ok
+}
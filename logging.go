@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type LogConfig struct {
+	Format string `toml:"format" comment:"text or json"`
+	Level  string `toml:"level" comment:"debug, info, warn or error"`
+}
+
+// logLevel can be changed by reloading the config
+var logLevel slog.LevelVar
+
+func parseLogLevel(name string) (level slog.Level, err error) {
+	if name != "" {
+		if err = level.UnmarshalText([]byte(name)); err != nil {
+			err = fmt.Errorf("invalid log level %q", name)
+		}
+	}
+	return
+}
+
+// setupLogging writes logs to stderr as text (the default) or one JSON object
+// per line
+func setupLogging(config LogConfig) error {
+	level, err := parseLogLevel(config.Level)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(level)
+
+	opts := &slog.HandlerOptions{Level: &logLevel}
+	var h slog.Handler
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", config.Format)
+	}
+	slog.SetDefault(slog.New(requestHandler{h}))
+	return nil
+}
+
+// logFailure logs a failed request, with whatever the engine wrote to stderr
+// if err carries it
+func logFailure(ctx context.Context, err error, attrs ...any) {
+	attrs = append(attrs, "err", err)
+	if stderr := engineStderr(err); stderr != "" {
+		attrs = append(attrs, "stderr", stderr)
+	}
+	slog.ErrorContext(ctx, "Request failed", attrs...)
+}
+
+// requestHandler adds the request ID, API key name and JWT subject to lines
+// logged with the context of a request
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h requestHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestIDFrom(ctx); id != "" {
+		r.AddAttrs(slog.String("uuid", id))
+	}
+	if key := apiKeyFrom(ctx); key != "" {
+		r.AddAttrs(slog.String("key", key))
+	}
+	if sub := subjectFrom(ctx); sub != "" {
+		r.AddAttrs(slog.String("sub", sub))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name)}
+}
+
+func fatal(err error) {
+	slog.Error("Fatal error", "err", err)
+	os.Exit(1)
+}
@@ -1,33 +1,88 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
+	"math"
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
-	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/google/uuid"
-	"github.com/pelletier/go-toml/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var engine Engine
+var router *engineRouter
 var globalConfigPath = "/etc/wbot/server.conf"
 
+// flagOverrides are config values set on the command line, which take
+// precedence over the config file and the environment
+var flagOverrides struct {
+	port      int
+	logLevel  string
+	logFormat string
+}
+
 type ServerConfig struct {
-	Port int `toml:"port"`
+	Port           int               `toml:"port"`
+	Listen         string            `toml:"listen" comment:"Address to listen on instead of port, such as \"127.0.0.1:8080\", or\n\"unix:/run/wbot/wbot.sock\" for a Unix socket"`
+	SocketMode     string            `toml:"socket_mode" comment:"Permissions of the Unix socket, in octal; 0660 by default"`
+	SocketOwner    string            `toml:"socket_owner" comment:"Owner of the Unix socket, \"user\", \"user:group\" or \":group\""`
+	Listeners      []ListenerConfig  `toml:"listeners" comment:"Serve the API on several listeners, each configured on its own, instead of\nlisten or port"`
+	HTTP3Addr      string            `toml:"http3_addr" comment:"Also serve HTTP/3 on this UDP address, such as \":443\", advertised with\nAlt-Svc; needs TLS"`
+	ProxyProtocol  bool              `toml:"proxy_protocol" comment:"Read the client address from a PROXY protocol header (v1 or v2) on each\nconnection, as sent by HAProxy or a cloud load balancer"`
+	GrpcPort       int               `toml:"grpc_port" comment:"Also serve the gRPC service in wbotpb/wbot.proto on this port; disabled if 0"`
+	Compression    CompressionConfig `toml:"compression"`
+	CacheControl   map[string]string `toml:"cache_control" comment:"Cache-Control header for successful responses per endpoint, such as\n\"/solve\"; max-age=midnight lasts until the next midnight UTC"`
+	Priority       map[string]string `toml:"priority" comment:"Priority (high, normal or low) of queued engine work per endpoint, such as\n\"/simulate\""`
+	AdminToken     string            `toml:"admin_token" comment:"Bearer token for /admin endpoints, granting every permission. The admin\nendpoints are disabled if neither this nor [server.oidc] is set"`
+	AdminTokenFile string            `toml:"admin_token_file" comment:"Read admin_token from this file instead"`
+	DebugAddr      string            `toml:"debug_addr" comment:"Serve pprof and other debugging endpoints on this address. Keep it private"`
+	Auth           AuthConfig        `toml:"auth"`
+	OIDC           OIDCConfig        `toml:"oidc" comment:"Let admins log in with OpenID Connect instead of sharing admin_token"`
+	RateLimit      RateLimitConfig   `toml:"rate_limit" comment:"Limit each client IP to rate requests per second, with bursts of up to\nburst; disabled if rate is 0"`
+	TrustedProxies []string          `toml:"trusted_proxies" comment:"Reverse proxies whose X-Forwarded-For and X-Real-IP headers are believed"`
+	TLSCert        string            `toml:"tls_cert" comment:"Serve HTTPS and gRPC over TLS with this PEM encoded certificate and key"`
+	TLSKey         string            `toml:"tls_key"`
+	ACME           ACMEConfig        `toml:"acme" comment:"Obtain certificates for domains from Let's Encrypt instead of tls_cert and\ntls_key"`
+	DebugTLS       DebugTLSConfig    `toml:"debug_tls" comment:"Serve debug_addr over TLS"`
+	CORS           CORSConfig        `toml:"cors"`
+	HTTP           HTTPConfig        `toml:"http" comment:"Timeouts and limits of HTTP connections, against clients that tie them up"`
+	ShutdownDelay  int               `toml:"shutdown_delay" comment:"Milliseconds /readyz fails before the listeners close on SIGTERM, for load\nbalancers to stop sending requests"`
+	ExposeStderr   bool              `toml:"expose_stderr" comment:"Include the end of the engine's stderr in error responses"`
 }
 
 type ConfigFile struct {
-	Server ServerConfig `toml:"server"`
-	Engine BotConfig    `toml:"engine"`
+	Server    ServerConfig      `toml:"server"`
+	Engine    BotConfig         `toml:"engine"`
+	Daily     DailyConfig       `toml:"daily"`
+	Game      GameConfig        `toml:"game"`
+	Words     WordsConfig       `toml:"words" comment:"What words may consist of, in requests and word lists alike"`
+	Openers   OpenersConfig     `toml:"openers"`
+	Jobs      JobConfig         `toml:"jobs"`
+	Tracing   TracingConfig     `toml:"tracing"`
+	Log       LogConfig         `toml:"log" comment:"Logs go to stderr"`
+	AccessLog AccessLogConfig   `toml:"access_log"`
+	Errors    ErrorReportConfig `toml:"errors" comment:"Report internal errors and panics to Sentry and/or a webhook"`
+
+	Dictionaries map[string]DictionaryConfig `toml:"dictionaries" comment:"Dictionaries that requests can choose with dict=, each solved with its own\nindex by an engine configured like [engine]"`
 }
 
-var words []string
+var dict atomic.Pointer[Dictionary]
+
+// exposeStderr includes engine stderr in error responses, for debugging
+var exposeStderr bool
 
 func enforceMethod(w http.ResponseWriter, r *http.Request, allowed ...string) error {
 	for _, allow := range allowed {
@@ -39,17 +94,20 @@ func enforceMethod(w http.ResponseWriter, r *http.Request, allowed ...string) er
 	w.Header().Add("Allow", strings.Join(allowed, ", "))
 	status := http.StatusMethodNotAllowed
 	msg := http.StatusText(status)
-	http.Error(w, msg, status)
+	httpError(w, msg, status)
 	return errors.New(msg)
 }
 
+// wordValid reports whether word is letters of wordScripts, as many as any
+// dictionary allows, once normalized to NFC
 func wordValid(word string) bool {
-	if len(word) != 5 {
+	letters := []rune(norm.NFC.String(word))
+	if !validLengths[len(letters)] {
 		return false
 	}
 
-	for _, c := range word {
-		if !unicode.IsLetter(c) || c > unicode.MaxASCII {
+	for _, c := range letters {
+		if !letterValid(c) {
 			return false
 		}
 	}
@@ -57,158 +115,462 @@ func wordValid(word string) bool {
 	return true
 }
 
-func internalError(w http.ResponseWriter, err error, id uuid.UUID) {
-	log.Printf("(uuid=%v) error: %v\n", id, err)
-	status := http.StatusInternalServerError
-	if _, ok := err.(TimeoutError); ok {
-		status = http.StatusServiceUnavailable
-	}
-	msg := fmt.Sprintf(
-		"%d - %s\nThe developers will know what to do with this: %v",
-		status,
-		http.StatusText(status),
-		id,
-	)
-	http.Error(w, msg, status)
-}
-
-func getIP(r *http.Request) string {
-	proxyFor := r.Header.Get("X-Real-IP")
-	if len(proxyFor) > 0 {
-		return proxyFor
+func internalError(w http.ResponseWriter, r *http.Request, err error) {
+	id := requestIDFrom(r.Context())
+	if errors.Is(err, context.Canceled) {
+		slog.Info("Client went away", "uuid", id)
+		return
 	}
 
-	return r.RemoteAddr
+	p := Problem{
+		Type:   problemType("internal"),
+		Status: http.StatusInternalServerError,
+		UUID:   id,
+	}
+	if u, err := uuid.Parse(id); err == nil {
+		p.Instance = u.URN()
+	}
+	var stalled StalledError
+	if errors.As(err, &stalled) {
+		p.Type = problemType("engine-stalled")
+		p.Status = http.StatusBadGateway
+		p.Detail = stalled.Error()
+		p.Retryable = true
+	}
+	var truncated TruncatedError
+	if errors.As(err, &truncated) {
+		p.Type = problemType("output-truncated")
+		p.Status = http.StatusBadGateway
+		p.Detail = truncated.Error()
+	}
+	var argsErr InvalidArgsError
+	var wordErr UnknownWordError
+	var indexErr IndexError
+	switch {
+	case errors.As(err, &argsErr):
+		p.Type = problemType("invalid-request")
+		p.Status = http.StatusBadRequest
+		p.Detail = argsErr.Error()
+	case errors.As(err, &wordErr):
+		p.Type = problemType("unknown-word")
+		p.Status = http.StatusBadRequest
+		p.Detail = wordErr.Error()
+	case errors.As(err, &indexErr):
+		p.Type = problemType("unavailable")
+		p.Status = http.StatusServiceUnavailable
+		p.Detail = indexErr.Error()
+		p.Retryable = true
+	}
+	switch e := err.(type) {
+	case TimeoutError:
+		p.Type = problemType("timeout")
+		p.Status = http.StatusServiceUnavailable
+		p.Detail = e.Error()
+		p.Retryable = true
+	case CircuitOpenError:
+		p.Type = problemType("circuit-open")
+		p.Status = http.StatusServiceUnavailable
+		p.Retryable = true
+		p.RetryAfter = max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+	case QueueFullError:
+		p.Type = problemType("queue-full")
+		p.Status = http.StatusTooManyRequests
+		p.Retryable = true
+		p.RetryAfter = max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+	}
+	if exposeStderr {
+		p.Stderr = engineStderr(err)
+	}
+	logFailure(r.Context(), err, "status", p.Status)
+	if reportable(err) {
+		reportError(r.Context(), r, err, nil)
+	}
+	writeProblem(w, p)
 }
 
-func writeJSON(w http.ResponseWriter, data any, id uuid.UUID) {
+func writeJSON(w http.ResponseWriter, r *http.Request, data any) {
 	w.Header().Add("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		internalError(w, err, id)
+		internalError(w, r, err)
 	}
 }
 
 func solveWord(w http.ResponseWriter, r *http.Request) {
-	if enforceMethod(w, r, "GET") != nil {
+	if enforceMethod(w, r, "GET", "POST") != nil {
 		return
 	}
 
-	id := uuid.New()
+	id := requestIDFrom(r.Context())
 	ip := getIP(r)
 
 	r.ParseForm()
-	word := r.Form.Get("w")
-
-	if !wordValid(word) {
-		http.Error(w, "Invalid word", http.StatusBadRequest)
-		log.Printf("Invalid `w' parameter in /solve request from %v\n", ip)
+	req, err := parseSolveRequest(r)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/solve", "ip", ip, "err", err)
 		return
 	}
+	word := req.Word
+
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
 
-	log.Printf("(uuid=%v) /solve from %v, w=%s\n", id, ip, word)
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/solve", "ip", ip, "word", word, "engine", name)
 	start := time.Now()
 
-	data, err := engine.Solve(word)
-	if err != nil {
-		internalError(w, err, id)
+	if wantsStream(r) {
+		err := streamSolve(w, r, id, word, eng)
+		router.record(name, err)
 	} else {
-		writeJSON(w, data, id)
+		data, err := eng.Solve(requestContext(r), word)
+		router.record(name, err)
+		if err != nil {
+			internalError(w, r, err)
+		} else {
+			writeResponse(w, r, data)
+		}
 	}
 
-	log.Printf("(uuid=%v) /solve done, took %v", id, time.Since(start))
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/solve", "duration", time.Since(start))
 }
 
 func coachWord(w http.ResponseWriter, r *http.Request) {
-	if enforceMethod(w, r, "GET") != nil {
+	if enforceMethod(w, r, "GET", "POST") != nil {
 		return
 	}
 
-	id := uuid.New()
 	ip := getIP(r)
 
 	r.ParseForm()
-	word := r.Form.Get("w")
-
-	if !wordValid(word) {
-		http.Error(w, "Invalid target word", http.StatusBadRequest)
-		log.Printf("Invalid `w' parameter in /coach request from %v\n", ip)
+	req, err := parseCoachRequest(r)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/coach", "ip", ip, "err", err)
 		return
 	}
 
-	guessesStr := r.Form.Get("guess")
-	guesses := strings.Split(guessesStr, ",")
-	if len(guesses) == 0 {
-		http.Error(w, "Expected guess", http.StatusBadRequest)
-		log.Printf("Empty `guess' parameter in /coach request from %v\n", ip)
+	if req.Word == "" {
+		coachFeedback(w, r, ip, req.Feedback)
 		return
 	}
 
-	for _, g := range guesses {
-		if !wordValid(g) {
-			http.Error(w, "Invalid word", http.StatusBadRequest)
-			log.Printf("Invalid `guess' parameter in /coach request from %v\n", ip)
-			return
-		}
-	}
+	word, guesses := req.Word, req.Guesses
+	guessesStr := strings.Join(guesses, ",")
 
-	log.Printf("(uuid=%v) /coach from %v, w=%s, guess=%s\n", id, ip, word, guessesStr)
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/coach", "ip", ip, "word", word, "guess", guessesStr, "engine", name)
 	start := time.Now()
 
-	data, err := engine.Coach(word, guesses)
+	data, err := eng.Coach(requestContext(r), word, guesses)
+	router.record(name, err)
 	if err != nil {
-		internalError(w, err, id)
+		internalError(w, r, err)
 	} else {
-		writeJSON(w, data, id)
+		writeResponse(w, r, data)
 	}
 
-	log.Printf("(uuid=%v) /coach done, took %v\n", id, time.Since(start))
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/coach", "duration", time.Since(start))
+}
+
+func applyFlagOverrides(config *ConfigFile) {
+	if flagOverrides.port != 0 {
+		config.Server.Port = flagOverrides.port
+		config.Server.Listen = ""
+		config.Server.Listeners = nil
+	}
+	if flagOverrides.logLevel != "" {
+		config.Log.Level = flagOverrides.logLevel
+	}
+	if flagOverrides.logFormat != "" {
+		config.Log.Format = flagOverrides.logFormat
+	}
 }
 
 func loadConfig() (config *ConfigFile, err error) {
-	log.Printf("Reading server config at %s", globalConfigPath)
+	slog.Info("Reading server config", "path", globalConfigPath)
 
-	tomlFile, err := os.Open(globalConfigPath)
-	if err != nil {
-		return
+	config = &ConfigFile{Server: ServerConfig{Port: 8080}, Daily: DailyConfig{Enabled: true}}
+
+	// A container may well be configured through the environment alone
+	err = decodeConfigFile(globalConfigPath, configFileFormat(globalConfigPath), config)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && envConfigured():
+		slog.Info("No server config, using the environment")
+	case err != nil:
+		return nil, err
+	}
+	if err = decodeIncludes(config); err != nil {
+		return nil, err
 	}
-	defer tomlFile.Close()
 
-	config = &ConfigFile{Server: ServerConfig{Port: 8080}}
+	if err = applyEnv(config); err != nil {
+		return nil, err
+	}
+	applyFlagOverrides(config)
+	if err = config.loadSecrets(); err != nil {
+		return nil, err
+	}
+	if err = config.check(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
-	decode := toml.NewDecoder(tomlFile)
-	if err = decode.Decode(config); err != nil {
-		config = nil
-		return
+	slog.Info("Server config loaded")
+	return config, nil
+}
+
+func newEngine(config BotConfig) (eng Engine, err error) {
+	switch {
+	case config.Builtin:
+		slog.Info("Using builtin solver")
+		return NewBuiltinEngine(), nil
+	case len(config.Backends) > 0:
+		eng, err = NewFailoverEngine(config)
+	case config.GrpcAddr != "":
+		slog.Info("Using remote engine", "addr", config.GrpcAddr)
+		eng, err = NewGrpcEngine(config)
+	default:
+		eng, err = NewBot(config)
 	}
 
-	log.Print("Server config loaded")
-	return
+	switch {
+	case err != nil && !config.Fallback:
+		return nil, err
+	case err != nil:
+		slog.Warn("Engine unavailable, running builtin solver in degraded mode", "err", err)
+		return NewBuiltinEngine(), nil
+	case config.Fallback:
+		return &FallbackEngine{primary: eng, fallback: NewBuiltinEngine()}, nil
+	default:
+		return eng, nil
+	}
 }
 
 func main() {
 	log.SetFlags(0)
 
+	if len(os.Args) > 1 && os.Args[1] == "precompute" {
+		runPrecompute(os.Args[2:])
+		return
+	}
+
+	flag.StringVar(&globalConfigPath, "config", globalConfigPath, "path of the server config")
+	flag.StringVar(&configFormat, "config-format", "", "format of the server config, toml, yaml or json; by default told by its extension")
+	flag.IntVar(&flagOverrides.port, "port", 0, "port to listen on, overriding the config")
+	flag.StringVar(&flagOverrides.logLevel, "log-level", "", "log level, overriding the config")
+	flag.StringVar(&flagOverrides.logFormat, "log-format", "", "log format, text or json, overriding the config")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	checkOnly := flag.Bool("check-config", false, "check the config and engines, and exit nonzero if anything is wrong")
+	printDefault := flag.Bool("print-default-config", false, "print a commented config with every setting at its default and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("wbot-server", buildVersion())
+		return
+	}
+	if *printDefault {
+		if err := printDefaultConfig(os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if *checkOnly {
+		if !checkConfig() {
+			os.Exit(1)
+		}
+		return
+	}
+
 	config, err := loadConfig()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+	reloadState.started, reloadState.current = config, config
+	// Before any engine, whose opener is looked up in the background
+	setupWords(config)
+	if err := setupLogging(config.Log); err != nil {
+		fatal(err)
+	}
+	slog.Info("Starting wbot-server", "version", buildVersion())
+	if err := setupSystemd(); err != nil {
+		fatal(err)
+	}
+
+	if err := setupErrorReports(config.Errors); err != nil {
+		fatal(err)
+	}
+	defer flushErrorReports()
+
+	shutdownTracing, err := setupTracing(config.Tracing)
+	if err != nil {
+		fatal(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	engine, err = newEngine(config.Engine)
+	if err != nil {
+		fatal(err)
+	}
+
+	if config.Engine.Shadow != nil {
+		shadowConfig := config.Engine.shadowConfig()
+		slog.Info("Shadowing traffic to candidate engine", "engine", shadowConfig.displayName())
+		candidate, err := newEngine(shadowConfig)
+		if err == nil {
+			engine, err = NewShadowEngine(engine, candidate, shadowConfig)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	if path := config.Engine.Cache.PrecomputedPath; path != "" {
+		precomputed, err := NewPrecomputedEngine(engine, path, botNamed(config.Engine.displayName()))
+		if err != nil {
+			fatal(err)
+		}
+		slog.Info("Serving precomputed solves", "words", precomputed.cache.count, "path", path)
+		engine = precomputed
+	}
+
+	var canary Engine
+	if config.Engine.Canary != nil {
+		canaryConfig := config.Engine.canaryConfig()
+		slog.Info("Routing traffic to canary engine", "percent", config.Engine.CanaryPercent, "engine", canaryConfig.displayName())
+		if canary, err = newEngine(canaryConfig); err != nil {
+			slog.Warn("Canary engine unavailable", "err", err)
+		}
+	}
+
+	router = newEngineRouter(engine, canary, config.Engine.CanaryPercent)
+	defer router.Close()
+
+	slog.Info("Loading words")
+	d, err := loadDictionary(engine, config.Engine)
+	if err != nil {
+		fatal(err)
+	}
+	dict.Store(d)
+	slog.Info("Read words", "words", len(d.Words), "answers", len(d.Answers))
+
+	precomputeAll("", d.Answers)
+	// Before jobs restart, as they may use the dictionaries
+	if err := setupDictionaries(config); err != nil {
+		fatal(err)
+	}
+
+	if config.Daily.Enabled {
+		if daily, err = newDailyPuzzle(config.Daily, ofLength(d.Answers, wordLengths[0])); err != nil {
+			fatal(err)
+		}
+	}
+	games = newGameStore(config.Game)
+	if jobs, err = newJobStore(config.Jobs); err != nil {
+		fatal(err)
+	}
+	go loadOpeners(engine, config.Openers)
+
+	if trustedProxies, err = parseCIDRs("trusted proxies", config.Server.TrustedProxies); err != nil {
+		fatal(err)
+	}
+	keyQuotas = newQuotas(config.Server.Auth.Limits)
+	limiter, err := newIPLimiter(config.Server.RateLimit)
+	if err != nil {
+		fatal(err)
+	}
+	ipLimits.Store(limiter)
+	adminToken = config.Server.AdminToken
+	exposeStderr = config.Server.ExposeStderr
+	if adminOIDC, err = newOIDCLogin(config.Server.OIDC); err != nil {
+		fatal(err)
+	}
+	go handleSighup()
+	watchFiles(config)
+
+	debugTLSConfig, err := debugTLS(config.Server)
+	if err != nil {
+		fatal(err)
+	}
+
+	auth, err := newAuthenticator(config.Server.Auth)
+	if err != nil {
+		fatal(err)
+	}
+	checkRoutes(config.Server)
+
+	// With client certificates required on the debug listener, the admin
+	// endpoints move there
+	debugMux := http.NewServeMux()
+	registerProbes(debugMux)
+	adminOnDebug := debugTLSConfig != nil && debugTLSConfig.ClientCAs != nil
+	if adminOnDebug {
+		registerRoutes(nil, debugMux, config.Server, auth)
 	}
 
-	bot, err := NewBot(config.Engine)
+	listeners, err := apiListeners(config.Server)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
-	defer bot.Close()
-	engine = bot
 
-	log.Println("Loading words")
-	words, err = engine.WordList()
+	tlsConfig, err := serverTLS(config.Server)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
-	log.Printf("Read %d words\n", len(words))
 
-	http.HandleFunc("/solve", solveWord)
-	http.HandleFunc("/coach", coachWord)
+	if config.Server.GrpcPort != 0 || len(activated["grpc"]) > 0 {
+		go mustServe(func() error {
+			return serveGrpc(config.Server, tlsConfig)
+		})
+	}
+	if config.Server.DebugAddr != "" || len(activated["debug"]) > 0 {
+		go mustServe(func() error {
+			return serveDebug(config.Server.DebugAddr, debugMux, debugTLSConfig, config.Server.HTTP)
+		})
+	}
+
+	// Every listener shares the middleware, but has its own endpoints
+	handler, err := accessLog(compress(cors(recoverPanics(limitIPs(listenerMux)), config.Server.CORS), config.Server.Compression), config.AccessLog)
+	if err != nil {
+		fatal(err)
+	}
+	handler = requestIDs(handler)
+	for _, l := range listeners {
+		mux := http.NewServeMux()
+		registerProbes(mux)
+		var apiMux, adminMux *http.ServeMux
+		if l.config.serves("api") {
+			apiMux = mux
+			mux.Handle("/metrics", promhttp.Handler())
+		}
+		if l.config.serves("admin") && !adminOnDebug {
+			adminMux = mux
+		}
+		if apiMux == nil && adminMux == nil {
+			slog.Warn("Listener serves nothing, as the admin endpoints are on debug_addr", "listener", l.config)
+		}
+		listenerAuth := auth
+		if l.config.Anonymous {
+			listenerAuth = nil
+		}
+		registerRoutes(apiMux, adminMux, config.Server, listenerAuth)
+
+		listenerTLS := tlsConfig
+		if l.config.Plaintext {
+			listenerTLS = nil
+		}
+		h := onListener(handler, mux)
+		if l.config.HTTP3Addr != "" {
+			h = serveHTTP3(l.config.HTTP3Addr, h, listenerTLS, config.Server.HTTP)
+		}
+		go mustServe(func() error {
+			return serve(l, h, listenerTLS, config.Server.HTTP)
+		})
+	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Server.Port), nil))
+	ready.Store(true)
+	sdNotify("READY=1")
+	// Returning runs the deferred calls that close the engines and flush error
+	// reports and traces
+	handleShutdown(time.Duration(config.Server.ShutdownDelay) * time.Millisecond)
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wbot_http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wbot_http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests by route.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2.5, 12),
+	}, []string{"route", "method"})
+
+	execDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wbot_engine_exec_duration_seconds",
+		Help:    "Time taken by engine invocations, not counting time spent queued.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"engine", "op"})
+
+	engineTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wbot_engine_timeouts_total",
+		Help: "Engine invocations that timed out waiting for a worker (queue) or running (engine).",
+	}, []string{"engine", "kind"})
+)
+
+var (
+	queueDepthDesc   = prometheus.NewDesc("wbot_queue_depth", "Engine invocations waiting for a worker.", []string{"queue"}, nil)
+	queueRejectDesc  = prometheus.NewDesc("wbot_queue_rejected_total", "Engine invocations rejected because the queue was full.", []string{"queue"}, nil)
+	workersDesc      = prometheus.NewDesc("wbot_workers", "Engine workers.", []string{"queue"}, nil)
+	workersBusyDesc  = prometheus.NewDesc("wbot_workers_busy", "Engine workers running an invocation.", []string{"queue"}, nil)
+	cacheHitsDesc    = prometheus.NewDesc("wbot_cache_hits_total", "Result cache hits.", []string{"engine"}, nil)
+	cacheMissesDesc  = prometheus.NewDesc("wbot_cache_misses_total", "Result cache misses.", []string{"engine"}, nil)
+	cacheEntriesDesc = prometheus.NewDesc("wbot_cache_entries", "Entries in the local result cache.", []string{"engine"}, nil)
+)
+
+// statusCollector reports what /status does
+type statusCollector struct{}
+
+func (statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{queueDepthDesc, queueRejectDesc, workersDesc, workersBusyDesc, cacheHitsDesc, cacheMissesDesc, cacheEntriesDesc} {
+		ch <- d
+	}
+}
+
+func (statusCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, q := range queueStatuses() {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(q.Depth), name)
+		ch <- prometheus.MustNewConstMetric(queueRejectDesc, prometheus.CounterValue, float64(q.Rejected), name)
+		ch <- prometheus.MustNewConstMetric(workersDesc, prometheus.GaugeValue, float64(q.Workers), name)
+		ch <- prometheus.MustNewConstMetric(workersBusyDesc, prometheus.GaugeValue, float64(q.Busy), name)
+	}
+	for name, c := range cacheStatuses() {
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(c.Hits), name)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(c.Misses), name)
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(c.Entries), name)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(statusCollector{})
+}
+
+func observeExec(engine string, args []string, d time.Duration, err error) {
+	op := ""
+	if len(args) > 0 {
+		op = args[0]
+	}
+	execDuration.WithLabelValues(engine, op).Observe(d.Seconds())
+	execLatency.observe(engineOp(engine, args), d)
+
+	if _, ok := err.(TimeoutError); ok {
+		engineTimeouts.WithLabelValues(engine, "engine").Inc()
+	}
+}
+
+func instrument(route string, h http.Handler) http.Handler {
+	labels := prometheus.Labels{"route": route}
+	h = timed(route, h)
+	h = promhttp.InstrumentHandlerDuration(httpDuration.MustCurryWith(labels), h)
+	return promhttp.InstrumentHandlerCounter(httpRequests.MustCurryWith(labels), h)
+}
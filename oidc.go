@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	adminSessionCookie = "wbot_admin"
+	oidcStateCookie    = "wbot_oidc_state"
+	adminSessionTTL    = 8 * time.Hour
+)
+
+// Admin permissions, granted to groups by the roles table of the OIDC config
+const (
+	permWorkers    = "workers"
+	permDictionary = "dictionary"
+	permConfig     = "config"
+)
+
+var adminPermissions = []string{permWorkers, permDictionary, permConfig}
+
+type OIDCConfig struct {
+	Issuer       string `toml:"issuer"`
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	// ClientSecretFile holds the client secret instead of ClientSecret
+	ClientSecretFile string `toml:"client_secret_file" comment:"Read client_secret from this file instead"`
+	RedirectURL      string `toml:"redirect_url" comment:"Must point at /v1/admin/callback"`
+	// Scopes requested besides openid, "profile" by default
+	Scopes []string `toml:"scopes"`
+	// GroupsClaim names the ID token claim listing the user's groups or roles
+	GroupsClaim string `toml:"groups_claim" comment:"ID token claim listing the admin's groups"`
+	// Roles maps each permission to the groups granted it
+	Roles map[string][]string `toml:"roles" comment:"Groups granted each permission (workers, dictionary and config)"`
+}
+
+type AdminSession struct {
+	Subject     string    `json:"subject"`
+	Groups      []string  `json:"groups"`
+	Permissions []string  `json:"permissions"`
+	Expires     time.Time `json:"expires"`
+}
+
+type oidcLogin struct {
+	config   OIDCConfig
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	// Sessions are signed with a key that only lives as long as the process
+	key []byte
+}
+
+// adminOIDC is nil unless admins log in with OpenID Connect
+var adminOIDC *oidcLogin
+
+func newOIDCLogin(config OIDCConfig) (*oidcLogin, error) {
+	if config.Issuer == "" {
+		return nil, nil
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.Scopes == nil {
+		config.Scopes = []string{"profile"}
+	}
+	for perm := range config.Roles {
+		if !slices.Contains(adminPermissions, perm) {
+			return nil, errors.New("unknown admin permission " + perm)
+		}
+	}
+
+	provider, err := oidc.NewProvider(context.Background(), config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &oidcLogin{
+		config: config,
+		oauth: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, config.Scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		key:      key,
+	}, nil
+}
+
+func (o *oidcLogin) permissions(groups []string) []string {
+	var perms []string
+	for _, perm := range adminPermissions {
+		for _, g := range o.config.Roles[perm] {
+			if slices.Contains(groups, g) {
+				perms = append(perms, perm)
+				break
+			}
+		}
+	}
+	return perms
+}
+
+func (o *oidcLogin) sign(payload string) string {
+	mac := hmac.New(sha256.New, o.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (o *oidcLogin) encodeSession(s AdminSession) string {
+	data, _ := json.Marshal(s)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + o.sign(payload)
+}
+
+// session is the unexpired admin session in the request's cookie
+func (o *oidcLogin) session(r *http.Request) (AdminSession, bool) {
+	var s AdminSession
+	cookie, err := r.Cookie(adminSessionCookie)
+	if err != nil {
+		return s, false
+	}
+
+	payload, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(o.sign(payload))) {
+		return s, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(data, &s) != nil {
+		return s, false
+	}
+	return s, time.Now().Before(s.Expires)
+}
+
+func randomState() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// adminLogin redirects to the identity provider
+func adminLogin(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+	if adminOIDC == nil {
+		httpError(w, "OpenID Connect login disabled", http.StatusNotFound)
+		return
+	}
+
+	state := randomState()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, adminOIDC.oauth.AuthCodeURL(state, oidc.Nonce(state)), http.StatusFound)
+}
+
+// adminCallback is where the identity provider sends the admin back to, and
+// starts their session
+func adminCallback(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+	if adminOIDC == nil {
+		httpError(w, "OpenID Connect login disabled", http.StatusNotFound)
+		return
+	}
+
+	state, err := r.Cookie(oidcStateCookie)
+	if err != nil || r.URL.Query().Get("state") != state.Value {
+		httpError(w, "Invalid login state", http.StatusBadRequest)
+		return
+	}
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		httpError(w, "Login failed: "+msg, http.StatusUnauthorized)
+		return
+	}
+
+	ctx := requestContext(r)
+	token, err := adminOIDC.oauth.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		httpError(w, "Login failed", http.StatusUnauthorized)
+		slog.WarnContext(r.Context(), "OpenID Connect code exchange failed", "ip", getIP(r), "err", err)
+		return
+	}
+	raw, _ := token.Extra("id_token").(string)
+	idToken, err := adminOIDC.verifier.Verify(ctx, raw)
+	if err == nil && idToken.Nonce != state.Value {
+		err = errors.New("nonce mismatch")
+	}
+	if err != nil {
+		httpError(w, "Login failed", http.StatusUnauthorized)
+		slog.WarnContext(r.Context(), "Invalid ID token", "ip", getIP(r), "err", err)
+		return
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		internalError(w, r, err)
+		return
+	}
+	var groups []string
+	switch v := claims[adminOIDC.config.GroupsClaim].(type) {
+	case string:
+		groups = []string{v}
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+
+	session := AdminSession{
+		Subject:     idToken.Subject,
+		Groups:      groups,
+		Permissions: adminOIDC.permissions(groups),
+		Expires:     time.Now().Add(adminSessionTTL),
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    adminOIDC.encodeSession(session),
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	slog.InfoContext(r.Context(), "Admin logged in", "ip", getIP(r), "sub", session.Subject, "permissions", session.Permissions)
+	writeJSON(w, r, session)
+}
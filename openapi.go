@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type openapiSchemas map[string]any
+
+// schema describes t, adding named struct types to s and referring to them
+func (s openapiSchemas) schema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "integer"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.schema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := s[t.Name()]; ok {
+			return ref
+		}
+		s[t.Name()] = nil
+
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = s.schema(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+
+		obj := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			obj["required"] = required
+		}
+		s[t.Name()] = obj
+		return ref
+	}
+	return map[string]any{}
+}
+
+func (s openapiSchemas) content(contentType string, v any) map[string]any {
+	return map[string]any{
+		contentType: map[string]any{"schema": s.schema(reflect.TypeOf(v))},
+	}
+}
+
+func (s openapiSchemas) operation(path string, op apiOperation) map[string]any {
+	params := []any{}
+	opParams := op.Params
+	if !strings.HasPrefix(path, "/admin/") && !slices.Contains(defaultDictionaryRoutes, path) {
+		opParams = append(opParams[:len(opParams):len(opParams)], dictParam, lenParam)
+	}
+	for _, p := range opParams {
+		in := "query"
+		if strings.Contains(path, "{"+p.Name+"}") {
+			in = "path"
+		}
+		param := map[string]any{
+			"name":     p.Name,
+			"in":       in,
+			"required": p.Required,
+			"schema":   map[string]any{"type": p.Type},
+		}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		params = append(params, param)
+	}
+
+	responses := map[string]any{
+		"default": map[string]any{
+			"description": "Error",
+			"content":     s.content("application/problem+json", Problem{}),
+		},
+	}
+	if op.Response != nil {
+		responses["200"] = map[string]any{
+			"description": "OK",
+			"content":     s.content("application/json", op.Response),
+		}
+	}
+
+	result := map[string]any{
+		"summary":    op.Summary,
+		"parameters": params,
+		"responses":  responses,
+	}
+	if op.Body != nil {
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content":  s.content("application/json", op.Body),
+		}
+	}
+	return result
+}
+
+func openapiSpec() map[string]any {
+	schemas := openapiSchemas{}
+	paths := map[string]any{}
+	for _, route := range apiRoutes() {
+		ops := map[string]any{}
+		for _, op := range route.Operations {
+			ops[strings.ToLower(op.Method)] = schemas.operation(route.Path, op)
+		}
+		paths[route.Path] = ops
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "wbot-server",
+			"version": apiVersion,
+		},
+		"servers":    []any{map[string]any{"url": "/v" + apiVersion}},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+var specOnce sync.Once
+var spec map[string]any
+
+func serveOpenapi(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	specOnce.Do(func() { spec = openapiSpec() })
+	writeJSON(w, r, spec)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// The best first guess only depends on the index, so it is computed once and
+// handed to the engine as WORDSMITH_OPENER instead of being recomputed by
+// every engine invocation
+func (b *Bot) openerPath() string {
+	return b.config.IndexPath + ".opener"
+}
+
+func (b *Bot) readOpener() (string, error) {
+	index, err := os.Stat(b.config.IndexPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(b.openerPath())
+	if err != nil {
+		return "", err
+	}
+	if info.ModTime().Before(index.ModTime()) {
+		return "", fmt.Errorf("%s is older than the index", b.openerPath())
+	}
+
+	data, err := os.ReadFile(b.openerPath())
+	if err != nil {
+		return "", err
+	}
+
+	opener := strings.TrimSpace(string(data))
+	if !wordValid(opener) {
+		return "", fmt.Errorf("invalid opener in %s", b.openerPath())
+	}
+	return opener, nil
+}
+
+func (b *Bot) loadOpener() {
+	if b.config.IndexPath == "" {
+		return
+	}
+
+	if opener, err := b.readOpener(); err == nil {
+		b.opener.Store(&opener)
+		return
+	}
+
+	var s Suggestion
+	args, err := suggestArgs(Constraints{Green: strings.Repeat("_", lengthsFor(b.config.dictionary)[0])})
+	if err == nil {
+		err = b.exec(withPriority(context.Background(), background), b.timeouts.Load().solve, &s, args...)
+	}
+	if err != nil || len(s.Best) == 0 {
+		slog.Warn("Failed to compute opener", "engine", b.config.displayName(), "err", err)
+		return
+	}
+
+	opener := s.Best[0].Word
+	b.opener.Store(&opener)
+	slog.Info("Computed opener", "engine", b.config.displayName(), "word", opener)
+
+	if err := os.WriteFile(b.openerPath(), []byte(opener+"\n"), 0o644); err != nil {
+		slog.Warn("Failed to persist opener", "err", err)
+	}
+}
+
+func (b *Bot) env() []string {
+	env := []string{fmt.Sprintf("WORDSMITH_INDEX=%s", b.config.IndexPath)}
+	if opener := b.opener.Load(); opener != nil {
+		env = append(env, fmt.Sprintf("WORDSMITH_OPENER=%s", *opener))
+	}
+	return env
+}
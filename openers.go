@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OpenersConfig struct {
+	Count     int    `toml:"count"`
+	CachePath string `toml:"cache_path" comment:"Opening guesses are read from here if it exists, or computed and written\nhere otherwise; delete it after changing the index"`
+}
+
+type openerList struct {
+	mu      sync.RWMutex
+	guesses []Guess
+}
+
+var openers openerList
+
+func (o *openerList) get() []Guess {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.guesses
+}
+
+func (o *openerList) set(guesses []Guess) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.guesses = guesses
+}
+
+func readOpeners(path string) ([]Guess, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var guesses []Guess
+	err = json.NewDecoder(f).Decode(&guesses)
+	return guesses, err
+}
+
+func writeOpeners(path string, guesses []Guess) error {
+	data, err := json.Marshal(guesses)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func computeOpeners(eng Engine, config OpenersConfig) ([]Guess, error) {
+	s, err := eng.Suggest(context.Background(), Constraints{Green: strings.Repeat("_", wordLengths[0])})
+	if err != nil {
+		return nil, err
+	}
+
+	guesses := s.Best
+	if config.Count > 0 && len(guesses) > config.Count {
+		guesses = guesses[:config.Count]
+	}
+	return guesses, nil
+}
+
+func loadOpeners(eng Engine, config OpenersConfig) {
+	if config.CachePath != "" {
+		guesses, err := readOpeners(config.CachePath)
+		if err == nil {
+			slog.Info("Read openers", "openers", len(guesses), "path", config.CachePath)
+			openers.set(guesses)
+			return
+		}
+		if !os.IsNotExist(err) {
+			slog.Warn("Failed to read openers cache", "err", err)
+		}
+	}
+
+	for {
+		guesses, err := computeOpeners(eng, config)
+		if err == nil {
+			slog.Info("Computed openers", "openers", len(guesses))
+			openers.set(guesses)
+
+			if config.CachePath != "" {
+				if err := writeOpeners(config.CachePath, guesses); err != nil {
+					slog.Warn("Failed to write openers cache", "err", err)
+				}
+			}
+			return
+		}
+
+		slog.Warn("Failed to compute openers, retrying in a minute", "err", err)
+		time.Sleep(time.Minute)
+	}
+}
+
+func listOpeners(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	guesses := openers.get()
+	if guesses == nil {
+		writeProblem(w, Problem{
+			Type:       problemType("unavailable"),
+			Status:     http.StatusServiceUnavailable,
+			Detail:     "Openers not computed yet",
+			Retryable:  true,
+			RetryAfter: 60,
+		})
+		return
+	}
+
+	r.ParseForm()
+	n, ok := formInt(r, "n", len(guesses), 1, math.MaxInt)
+	if !ok {
+		httpError(w, "Invalid n", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/openers", "ip", ip, "param", "n")
+		return
+	}
+
+	setDefaultCacheControl(w, "public, max-age=3600")
+	writeJSON(w, r, guesses[:min(n, len(guesses))])
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+)
+
+// defaultMaxOutput is how many bytes an engine invocation may write to stdout
+// unless max_output says otherwise
+const defaultMaxOutput = 1024 * 1024
+
+// TruncatedError means the engine wrote more output than it's allowed to
+type TruncatedError struct {
+	Size  int64
+	Limit int64
+}
+
+func (err TruncatedError) Error() string {
+	return fmt.Sprintf("engine output truncated: wrote %d bytes, limit is %d", err.Size, err.Limit)
+}
+
+// maxOutput is the output limit of an operation, the first engine argument
+func (config BotConfig) maxOutput(op string) int64 {
+	if n := config.MaxOutput[op]; n > 0 {
+		return n
+	}
+	if op == "session" {
+		return 64 * defaultMaxOutput
+	}
+	return defaultMaxOutput
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Engine invocations are run by the shared workers, unless their operation
+// has workers of its own, so that slow solves can't hold up coaching or
+// loading the word lists
+var poolOps = map[string]string{
+	"solve":   "solve",
+	"rank":    "solve",
+	"coach":   "coach",
+	"suggest": "coach",
+	"list":    "admin",
+}
+
+func (config BotConfig) poolWorkers() map[string]int {
+	return map[string]int{
+		"solve": config.SolveWorkers,
+		"coach": config.CoachWorkers,
+		"admin": config.AdminWorkers,
+	}
+}
+
+func (b *Bot) startWorkers(s *scheduler, n int) {
+	for range s.resize(n) {
+		go b.worker(s)
+	}
+}
+
+func (b *Bot) startPools() {
+	b.startWorkers(b.sched, b.config.MaxConcurrentUsers)
+
+	b.pools = map[string]*scheduler{}
+	for op, n := range b.config.poolWorkers() {
+		if n > 0 {
+			b.pools[op] = newScheduler(b.config.displayName()+" "+op, b.config.MaxQueue)
+			b.startWorkers(b.pools[op], n)
+		}
+	}
+}
+
+// pool is the scheduler for running the engine with args
+func (b *Bot) pool(args []string) *scheduler {
+	if len(args) > 0 {
+		if s, ok := b.pools[poolOps[args[0]]]; ok {
+			return s
+		}
+	}
+	return b.sched
+}
+
+// resize sets the number of shared workers, or of op's workers if given
+func (b *Bot) resize(op string, n int) error {
+	s := b.sched
+	if op != "" {
+		var ok bool
+		if s, ok = b.pools[op]; !ok {
+			return fmt.Errorf("engine %s has no %s workers", b.config.displayName(), op)
+		}
+	}
+	if n < 1 {
+		return errors.New("expected at least one worker")
+	}
+
+	slog.Info("Resizing workers", "queue", s.name, "workers", n)
+	b.startWorkers(s, n)
+	return nil
+}
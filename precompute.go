@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+func (b *Bot) precompute(words []string) {
+	if b.cache == nil {
+		return
+	}
+
+	name := b.config.displayName()
+	if b.config.Cache.MaxEntries < len(words) && b.config.Cache.RedisAddr == "" {
+		slog.Warn("Cache holds fewer entries than the words to precompute", "engine", name, "max_entries", b.config.Cache.MaxEntries, "words", len(words))
+	}
+
+	slog.Info("Precomputing solve reports", "engine", name, "words", len(words))
+	start := time.Now()
+	failures := 0
+
+	for _, word := range words {
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+
+		var reports []WordReport
+		args, err := solveArgs(word)
+		if err == nil {
+			err = b.execCached(withPriority(context.Background(), background), b.timeouts.Load().solve, &reports, args...)
+		}
+		if err != nil {
+			failures++
+		}
+	}
+
+	slog.Info("Precomputed solve reports", "engine", name, "words", len(words), "failed", failures, "duration", time.Since(start))
+}
+
+// precomputeAll precomputes words on the engines of dictionary, "" being the
+// engine's own
+func precomputeAll(dictionary string, words []string) {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	for _, b := range bots {
+		if b.config.Cache.Precompute && b.config.dictionary == dictionary {
+			go b.precompute(words)
+		}
+	}
+}
+
+// runPrecompute implements `wbot-server precompute -o path`
+func runPrecompute(args []string) {
+	flags := flag.NewFlagSet("precompute", flag.ExitOnError)
+	out := flags.String("o", "precomputed.db", "output file")
+	list := flags.String("list", "all", "word list to precompute, all or answers")
+	flags.StringVar(&globalConfigPath, "config", globalConfigPath, "path of the server config")
+	flags.StringVar(&configFormat, "config-format", "", "format of the server config, toml, yaml or json; by default told by its extension")
+	flags.Parse(args)
+
+	config, err := loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if err := setupLogging(config.Log); err != nil {
+		fatal(err)
+	}
+	setupWords(config)
+
+	eng, err := newEngine(config.Engine)
+	if err != nil {
+		fatal(err)
+	}
+	defer eng.Close()
+
+	d, err := loadDictionary(eng, config.Engine)
+	if err != nil {
+		fatal(err)
+	}
+	var words []string
+	switch *list {
+	case "all":
+		words = d.Words
+	case "answers":
+		words = d.Answers
+	default:
+		fatal(fmt.Errorf("invalid word list %q, expected all or answers", *list))
+	}
+	slog.Info("Precomputing solve reports", "words", len(words))
+
+	var mu sync.Mutex
+	results := make(map[string][]WordReport, len(words))
+	failures := 0
+
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < max(1, config.Engine.MaxConcurrentUsers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for word := range queue {
+				reports, err := eng.Solve(context.Background(), word)
+
+				mu.Lock()
+				if err != nil {
+					slog.Warn("Failed to solve", "word", word, "err", err)
+					failures++
+				} else {
+					results[foldWord(word)] = reports
+				}
+				if done := len(results) + failures; done%500 == 0 {
+					slog.Info("Progress", "done", done, "words", len(words))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+	for _, word := range words {
+		queue <- word
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := writeDiskCache(*out, results); err != nil {
+		fatal(err)
+	}
+	slog.Info("Wrote precomputed solve reports", "words", len(results), "path", *out, "failed", failures, "duration", time.Since(start))
+}
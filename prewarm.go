@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os/exec"
+	"time"
+)
+
+type warmProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr *tailBuffer
+}
+
+func (b *Bot) startWarm() (p *warmProcess, err error) {
+	cmd, err := b.command(context.Background(), "--args-from-stdin")
+	if err != nil {
+		return nil, err
+	}
+
+	p = &warmProcess{cmd: cmd, stderr: &tailBuffer{max: 4096}}
+	cmd.Stderr = p.stderr
+	if p.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if p.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err = b.start(cmd); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// spawnWarm starts a warm process to fill a slot of the pool, retrying with
+// backoff until one starts or the engine is closed, so that the slot isn't
+// lost to an engine that failed to start for a while
+func (b *Bot) spawnWarm() {
+	delay := time.Second
+	for {
+		p, err := b.startWarm()
+		if err == nil {
+			b.offerWarm(p)
+			return
+		}
+		slog.Warn("Failed to start warm engine process", "engine", b.config.displayName(), "delay", delay, "err", err)
+
+		select {
+		case <-b.done:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, time.Minute)
+	}
+}
+
+func (b *Bot) offerWarm(p *warmProcess) {
+	select {
+	case b.warm <- p:
+	case <-b.done:
+		p.kill()
+	}
+}
+
+func (b *Bot) takeWarm() *warmProcess {
+	select {
+	case p := <-b.warm:
+		go b.spawnWarm()
+		return p
+	default:
+		return nil
+	}
+}
+
+// stdinProcess takes a prewarmed process if there is one. Otherwise, with
+// args_from_stdin set, it starts one to pass arguments to through stdin rather
+// than argv, where they would show up in ps
+func (b *Bot) stdinProcess() (*warmProcess, error) {
+	if p := b.takeWarm(); p != nil {
+		return p, nil
+	}
+	if b.config.ArgsFromStdin {
+		return b.startWarm()
+	}
+	return nil, nil
+}
+
+func (b *Bot) drainWarm() (n int) {
+	for {
+		select {
+		case p := <-b.warm:
+			p.kill()
+			n++
+		default:
+			return
+		}
+	}
+}
+
+func (p *warmProcess) send(args []string) error {
+	err := json.NewEncoder(p.stdin).Encode(args)
+	if closeErr := p.stdin.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (p *warmProcess) kill() {
+	killGroup(p.cmd)
+	p.cmd.Wait()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Queued engine work is dispatched in order of priority. Background work,
+// like precomputing, only runs on otherwise idle workers
+type priority int
+
+const (
+	high priority = iota
+	normal
+	low
+	background
+
+	numPriorities = iota
+)
+
+var priorityNames = map[string]priority{
+	"high":   high,
+	"normal": normal,
+	"low":    low,
+}
+
+// A human mid-game is waiting on coaching, so it goes before solves, and
+// nobody is waiting on jobs
+var defaultPriorities = map[string]string{
+	"/coach":      "high",
+	"/ws/coach":   "high",
+	"/suggest":    "high",
+	"/jobs/solve": "low",
+}
+
+func (p priority) String() string {
+	for name, q := range priorityNames {
+		if p == q {
+			return name
+		}
+	}
+	if p == background {
+		return "background"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+type priorityKey struct{}
+
+func withPriority(ctx context.Context, p priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) priority {
+	if p, ok := ctx.Value(priorityKey{}).(priority); ok {
+		return p
+	}
+	return normal
+}
+
+func withRoutePriority(h http.Handler, p priority) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(withPriority(r.Context(), p)))
+	})
+}
+
+// routePriority is the configured priority for path, falling back to the
+// default
+func routePriority(config map[string]string, path string) priority {
+	name, ok := config[path]
+	if !ok {
+		name, ok = defaultPriorities[path]
+	}
+	if !ok {
+		return normal
+	}
+
+	p, ok := priorityNames[name]
+	if !ok {
+		slog.Warn("Ignoring unknown priority", "priority", name, "route", path)
+		return normal
+	}
+	return p
+}
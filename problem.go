@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Problem is an RFC 7807 error response
+type Problem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Instance   string `json:"instance,omitempty"`
+	UUID       string `json:"uuid,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+}
+
+func problemType(name string) string {
+	return "urn:wbot:problem:" + name
+}
+
+func statusProblem(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return problemType("invalid-request")
+	case http.StatusUnauthorized:
+		return problemType("unauthorized")
+	case http.StatusForbidden:
+		return problemType("forbidden")
+	case http.StatusNotFound:
+		return problemType("not-found")
+	case http.StatusMethodNotAllowed:
+		return problemType("method-not-allowed")
+	case http.StatusConflict:
+		return problemType("conflict")
+	case http.StatusServiceUnavailable:
+		return problemType("unavailable")
+	}
+	return problemType("internal")
+}
+
+func writeProblem(w http.ResponseWriter, p Problem) {
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.RetryAfter > 0 {
+		w.Header().Set("Retry-After", fmt.Sprint(p.RetryAfter))
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Del("Cache-Control")
+	h.Set("Content-Type", "application/problem+json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// httpError is a drop-in for http.Error with a problem+json body
+func httpError(w http.ResponseWriter, detail string, status int) {
+	writeProblem(w, Problem{
+		Type:      statusProblem(status),
+		Status:    status,
+		Detail:    detail,
+		Retryable: status == http.StatusServiceUnavailable,
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxies are the peers whose X-Forwarded-For and X-Real-IP headers
+// are believed
+var trustedProxies []*net.IPNet
+
+func parseCIDRs(what string, cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", what, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func inNets(nets []*net.IPNet, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor is the client address according to a request from a trusted
+// proxy: the last address in the X-Forwarded-For chain that isn't itself a
+// trusted proxy, or else X-Real-IP
+func forwardedFor(r *http.Request) string {
+	var chain []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(h, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				chain = append(chain, addr)
+			}
+		}
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr := clientHost(chain[i])
+		if net.ParseIP(addr) == nil {
+			// Anything before a malformed hop can't be trusted either
+			return ""
+		}
+		if i == 0 || !inNets(trustedProxies, addr) {
+			return addr
+		}
+	}
+	return r.Header.Get("X-Real-IP")
+}
+
+func getIP(r *http.Request) string {
+	if viaUnixSocket(r) || inNets(trustedProxies, clientHost(r.RemoteAddr)) {
+		if ip := forwardedFor(r); ip != "" {
+			return ip
+		}
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net"
+
+	"github.com/pires/go-proxyproto"
+)
+
+// acceptProxyProtocol reads the PROXY protocol header, v1 or v2, that load
+// balancers such as HAProxy and cloud NLBs send ahead of each connection, so
+// the client address in logs and rate limits is the real one. With
+// trusted_proxies set, connections from them must have the header and others
+// mustn't, so clients can't claim another address. Otherwise every
+// connection must have one
+func acceptProxyProtocol(l net.Listener) net.Listener {
+	return &proxyproto.Listener{
+		Listener: l,
+		ConnPolicy: func(opts proxyproto.ConnPolicyOptions) (proxyproto.Policy, error) {
+			if len(trustedProxies) == 0 {
+				return proxyproto.REQUIRE, nil
+			}
+			if _, ok := opts.Upstream.(*net.UnixAddr); ok {
+				return proxyproto.REQUIRE, nil
+			}
+			if inNets(trustedProxies, clientHost(opts.Upstream.String())) {
+				return proxyproto.REQUIRE, nil
+			}
+			return proxyproto.REJECT, nil
+		},
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type KeyLimit struct {
+	RequestsPerMinute int `toml:"requests_per_minute" comment:"Requests per minute; unlimited if 0"`
+	DailyQuota        int `toml:"daily_quota" comment:"Requests per day (UTC); unlimited if 0"`
+}
+
+// LimitsConfig applies to every API key and JWT subject, except those with
+// limits of their own in Keys. Zero means unlimited
+type LimitsConfig struct {
+	RequestsPerMinute int                 `toml:"requests_per_minute" comment:"Requests per minute; unlimited if 0"`
+	DailyQuota        int                 `toml:"daily_quota" comment:"Requests per day (UTC); unlimited if 0"`
+	Keys              map[string]KeyLimit `toml:"keys" comment:"Overrides for individual keys or subjects"`
+}
+
+type Usage struct {
+	Key               string    `json:"key"`
+	RequestsPerMinute int       `json:"requestsPerMinute,omitempty"`
+	MinuteRemaining   *int      `json:"minuteRemaining,omitempty"`
+	MinuteReset       time.Time `json:"minuteReset"`
+	DailyQuota        int       `json:"dailyQuota,omitempty"`
+	DailyRemaining    *int      `json:"dailyRemaining,omitempty"`
+	DailyReset        time.Time `json:"dailyReset"`
+}
+
+type keyUsage struct {
+	minute      time.Time
+	minuteCount int
+	day         time.Time
+	dayCount    int
+}
+
+// quotas counts requests per key in fixed windows: the current minute, and
+// the current day in UTC
+type quotas struct {
+	config LimitsConfig
+
+	mu    sync.Mutex
+	usage map[string]*keyUsage
+}
+
+// keyQuotas is shared by the HTTP and gRPC servers
+var keyQuotas = newQuotas(LimitsConfig{})
+
+func newQuotas(config LimitsConfig) *quotas {
+	return &quotas{config: config, usage: make(map[string]*keyUsage)}
+}
+
+// setConfig changes the limits, keeping the counts so far
+func (q *quotas) setConfig(config LimitsConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.config = config
+}
+
+// limitOf returns the limits of key. q.mu must be held
+func (q *quotas) limitOf(key string) KeyLimit {
+	if l, ok := q.config.Keys[key]; ok {
+		return l
+	}
+	return KeyLimit{RequestsPerMinute: q.config.RequestsPerMinute, DailyQuota: q.config.DailyQuota}
+}
+
+// window returns the usage of key, resetting counts of windows that have
+// passed. q.mu must be held
+func (q *quotas) window(key string, now time.Time) *keyUsage {
+	u, ok := q.usage[key]
+	if !ok {
+		u = &keyUsage{}
+		q.usage[key] = u
+	}
+	if minute := now.Truncate(time.Minute); !u.minute.Equal(minute) {
+		u.minute, u.minuteCount = minute, 0
+	}
+	if day := now.UTC().Truncate(24 * time.Hour); !u.day.Equal(day) {
+		u.day, u.dayCount = day, 0
+	}
+	return u
+}
+
+func (q *quotas) report(key string, u *keyUsage) Usage {
+	l := q.limitOf(key)
+	usage := Usage{
+		Key:               key,
+		RequestsPerMinute: l.RequestsPerMinute,
+		MinuteReset:       u.minute.Add(time.Minute),
+		DailyQuota:        l.DailyQuota,
+		DailyReset:        u.day.Add(24 * time.Hour),
+	}
+	if l.RequestsPerMinute > 0 {
+		remaining := max(l.RequestsPerMinute-u.minuteCount, 0)
+		usage.MinuteRemaining = &remaining
+	}
+	if l.DailyQuota > 0 {
+		remaining := max(l.DailyQuota-u.dayCount, 0)
+		usage.DailyRemaining = &remaining
+	}
+	return usage
+}
+
+// take counts a request by key, unless it would exceed its limits
+func (q *quotas) take(key string) (Usage, bool) {
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	l := q.limitOf(key)
+	u := q.window(key, now)
+	if (l.RequestsPerMinute > 0 && u.minuteCount >= l.RequestsPerMinute) || (l.DailyQuota > 0 && u.dayCount >= l.DailyQuota) {
+		return q.report(key, u), false
+	}
+	u.minuteCount++
+	u.dayCount++
+	return q.report(key, u), true
+}
+
+func (q *quotas) peek(key string) Usage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.report(key, q.window(key, time.Now()))
+}
+
+// quotaKey is who a request counts against: its API key, or its JWT subject
+func quotaKey(ctx context.Context) string {
+	if key := apiKeyFrom(ctx); key != "" {
+		return key
+	}
+	return subjectFrom(ctx)
+}
+
+// retryAfter is how long until the exhausted limit of u resets
+func (u Usage) retryAfter() time.Time {
+	if u.DailyRemaining != nil && *u.DailyRemaining == 0 {
+		return u.DailyReset
+	}
+	return u.MinuteReset
+}
+
+func setQuotaHeaders(h http.Header, u Usage) {
+	if u.MinuteRemaining != nil {
+		h.Set("X-RateLimit-Limit", strconv.Itoa(u.RequestsPerMinute))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(*u.MinuteRemaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(u.MinuteReset.Unix(), 10))
+	}
+	if u.DailyRemaining != nil {
+		h.Set("X-Quota-Limit", strconv.Itoa(u.DailyQuota))
+		h.Set("X-Quota-Remaining", strconv.Itoa(*u.DailyRemaining))
+		h.Set("X-Quota-Reset", strconv.FormatInt(u.DailyReset.Unix(), 10))
+	}
+}
+
+// limit rejects requests over their key's limits with 429
+func (q *quotas) limit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := quotaKey(r.Context())
+		if key == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		usage, ok := q.take(key)
+		setQuotaHeaders(w.Header(), usage)
+		if !ok {
+			writeProblem(w, Problem{
+				Type:       problemType("rate-limited"),
+				Status:     http.StatusTooManyRequests,
+				Detail:     "Rate limit or daily quota exceeded",
+				Retryable:  true,
+				RetryAfter: max(int(time.Until(usage.retryAfter()).Seconds())+1, 1),
+			})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (q *quotas) grpc(ctx context.Context) error {
+	key := quotaKey(ctx)
+	if key == "" {
+		return nil
+	}
+	if usage, ok := q.take(key); !ok {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit or daily quota exceeded until %s", usage.retryAfter().Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// serveUsage reports the remaining budget of the key making the request,
+// without counting against it
+func serveUsage(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	key := quotaKey(r.Context())
+	if key == "" {
+		httpError(w, "Requests without an API key are not limited", http.StatusNotFound)
+		return
+	}
+	usage := keyQuotas.peek(key)
+	setQuotaHeaders(w.Header(), usage)
+	writeJSON(w, r, usage)
+}
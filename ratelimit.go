@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitConfig limits each client IP to Rate requests per second, after an
+// initial Burst. Clients in Exempt are never limited
+type RateLimitConfig struct {
+	Rate   float64  `toml:"rate"`
+	Burst  int      `toml:"burst"`
+	Exempt []string `toml:"exempt" comment:"Addresses that are never limited"`
+}
+
+type ipBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type ipLimiter struct {
+	rate   rate.Limit
+	burst  int
+	exempt []*net.IPNet
+
+	mu      sync.Mutex
+	buckets map[string]*ipBucket
+	done    chan struct{}
+}
+
+// ipLimits holds nil if rate limiting is disabled. Reloading the config
+// replaces it
+var ipLimits atomic.Pointer[ipLimiter]
+
+func newIPLimiter(config RateLimitConfig) (*ipLimiter, error) {
+	if config.Rate <= 0 {
+		return nil, nil
+	}
+
+	exempt, err := parseCIDRs("rate limit exemption", config.Exempt)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ipLimiter{
+		rate:    rate.Limit(config.Rate),
+		burst:   max(config.Burst, 1),
+		exempt:  exempt,
+		buckets: make(map[string]*ipBucket),
+		done:    make(chan struct{}),
+	}
+
+	go l.prune()
+	return l, nil
+}
+
+// prune forgets clients whose buckets have been full for a while, so that
+// the map doesn't grow with every client ever seen
+func (l *ipLimiter) prune() {
+	refill := time.Duration(float64(l.burst) / float64(l.rate) * float64(time.Second))
+	idle := max(refill, time.Minute)
+	ticker := time.NewTicker(idle)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+
+		l.mu.Lock()
+		for ip, b := range l.buckets {
+			if time.Since(b.lastSeen) > idle {
+				delete(l.buckets, ip)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *ipLimiter) close() {
+	if l != nil {
+		close(l.done)
+	}
+}
+
+// allow takes a token from the bucket of ip, returning how long until one is
+// available if there is none
+func (l *ipLimiter) allow(ip string) (time.Duration, bool) {
+	if l == nil || inNets(l.exempt, ip) {
+		return 0, true
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &ipBucket{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.buckets[ip] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+// unlimitedPaths are polled by probes and Prometheus, which mustn't be told
+// to back off, so limitIPs lets them through
+var unlimitedPaths = []string{"/livez", "/readyz", "/metrics"}
+
+// limitIPs rejects requests from clients over their rate with 429, before
+// they reach the worker queue
+func limitIPs(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(unlimitedPaths, r.URL.Path) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if delay, ok := ipLimits.Load().allow(clientHost(getIP(r))); !ok {
+			writeProblem(w, Problem{
+				Type:       problemType("rate-limited"),
+				Status:     http.StatusTooManyRequests,
+				Detail:     "Too many requests from this address",
+				Retryable:  true,
+				RetryAfter: int(math.Ceil(delay.Seconds())),
+			})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func grpcLimitIP(ctx context.Context) error {
+	if delay, ok := ipLimits.Load().allow(clientHost(grpcPeer(ctx))); !ok {
+		return status.Errorf(codes.ResourceExhausted, "too many requests from this address, retry in %v", delay.Round(time.Millisecond))
+	}
+	return nil
+}
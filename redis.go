@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisTimeout = 100 * time.Millisecond
+
+// redisCache shares results between replicas, with the local cache in front
+// of it and used alone while Redis is unreachable
+type redisCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+	local  *lruCache
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func newRedisCache(config CacheConfig, local *lruCache) *redisCache {
+	prefix := config.RedisPrefix
+	if prefix == "" {
+		prefix = "wbot:"
+	}
+
+	return &redisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:         config.RedisAddr,
+			Password:     config.RedisPassword,
+			DialTimeout:  redisTimeout,
+			ReadTimeout:  redisTimeout,
+			WriteTimeout: redisTimeout,
+		}),
+		prefix: prefix,
+		ttl:    time.Duration(config.TTL) * time.Second,
+		local:  local,
+	}
+}
+
+func (c *redisCache) logError(err error) {
+	// Only log the first of a run of errors
+	if c.errors.Add(1) == 1 {
+		slog.Warn("Redis cache unavailable, using local cache", "err", err)
+	}
+}
+
+func (c *redisCache) get(key string) ([]byte, bool) {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			return data, true
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	switch {
+	case err == nil:
+		c.errors.Store(0)
+		c.hits.Add(1)
+		if c.local != nil {
+			c.local.add(key, data)
+		}
+		return data, true
+	case !errors.Is(err, redis.Nil):
+		c.logError(err)
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+func (c *redisCache) add(key string, data []byte) {
+	if c.local != nil {
+		c.local.add(key, data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+key, data, c.ttl).Err(); err != nil {
+		c.logError(err)
+	} else {
+		c.errors.Store(0)
+	}
+}
+
+func (c *redisCache) status() CacheStatus {
+	var status CacheStatus
+	if c.local != nil {
+		status = c.local.status()
+	}
+	status.RedisHits = c.hits.Load()
+	status.RedisMisses = c.misses.Load()
+	status.RedisUp = c.errors.Load() == 0
+	return status
+}
+
+func (c *redisCache) Close() {
+	c.client.Close()
+}
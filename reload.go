@@ -0,0 +1,237 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// timeoutConfig holds the timeouts of an engine in milliseconds
+type timeoutConfig struct {
+	solve, coach, queue, stall int
+}
+
+func (config BotConfig) timeouts() *timeoutConfig {
+	return &timeoutConfig{
+		solve: config.SolveTimeout,
+		coach: config.CoachTimeout,
+		queue: config.QueueTimeout,
+		stall: config.StallTimeout,
+	}
+}
+
+type ReloadStatus struct {
+	Applied         []string `json:"applied"`
+	RequiresRestart []string `json:"requiresRestart"`
+}
+
+// Settings that reloading the config applies, by their TOML path. Engine
+// settings are matched on their key alone, for every engine
+var (
+	liveSettings       = []string{"log.level", "server.rate_limit", "server.auth.limits"}
+	liveEngineSettings = []string{
+		"solve_timeout", "coach_timeout", "queue_timeout", "stall_timeout",
+		"max_concurrent_users", "solve_workers", "coach_workers", "admin_workers",
+	}
+)
+
+var reloadState struct {
+	mu      sync.Mutex
+	started *ConfigFile
+	current *ConfigFile
+}
+
+func liveSetting(path string, pools map[string]bool) bool {
+	for _, s := range liveSettings {
+		if path == s || strings.HasPrefix(path, s+".") {
+			return true
+		}
+	}
+	if strings.HasPrefix(path, "engine.") {
+		key := path[strings.LastIndex(path, ".")+1:]
+		return slices.Contains(liveEngineSettings, key) && !pools[key]
+	}
+	return false
+}
+
+// poolChanges lists the *_workers settings in config that would start or stop
+// an engine's own pool of workers. Pools are only set up when the engine
+// starts, so those take a restart
+func poolChanges(config *ConfigFile) map[string]bool {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	changes := map[string]bool{}
+	for _, c := range config.engines() {
+		b, ok := bots[c.displayName()]
+		if !ok {
+			continue
+		}
+		for op, n := range c.poolWorkers() {
+			if _, running := b.pools[op]; running != (n > 0) {
+				changes[op+"_workers"] = true
+			}
+		}
+	}
+	return changes
+}
+
+// configChanges lists the TOML paths of the settings that differ between a
+// and b
+func configChanges(path string, a, b reflect.Value) []string {
+	switch a.Kind() {
+	case reflect.Struct:
+		var changes []string
+		t := a.Type()
+		for i := range t.NumField() {
+			key := tomlKey(t.Field(i))
+			if key == "" {
+				continue
+			}
+			if path != "" {
+				key = path + "." + key
+			}
+			changes = append(changes, configChanges(key, a.Field(i), b.Field(i))...)
+		}
+		return changes
+	case reflect.Pointer:
+		if !a.IsNil() && !b.IsNil() {
+			return configChanges(path, a.Elem(), b.Elem())
+		}
+	case reflect.Slice:
+		if a.Len() == b.Len() && a.Type().Elem().Kind() == reflect.Struct {
+			var changes []string
+			for i := range a.Len() {
+				changes = append(changes, configChanges(path+"."+strconv.Itoa(i), a.Index(i), b.Index(i))...)
+			}
+			return changes
+		}
+	}
+
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return []string{path}
+	}
+	return nil
+}
+
+// reloadConfig rereads the config and applies the log level, rate limits,
+// quotas and engine timeouts and worker counts. Anything else that changed
+// since the server started is reported as requiring a restart
+func reloadConfig() (status ReloadStatus, err error) {
+	config, err := loadConfig()
+	if err != nil {
+		return
+	}
+
+	reloadState.mu.Lock()
+	defer reloadState.mu.Unlock()
+
+	// Check everything before applying anything
+	level, err := parseLogLevel(config.Log.Level)
+	if err != nil {
+		return
+	}
+	limiter := ipLimits.Load()
+	if !reflect.DeepEqual(config.Server.RateLimit, reloadState.current.Server.RateLimit) {
+		if limiter, err = newIPLimiter(config.Server.RateLimit); err != nil {
+			return
+		}
+	}
+
+	status = ReloadStatus{Applied: []string{}, RequiresRestart: []string{}}
+	pools := poolChanges(config)
+	for _, path := range configChanges("", reflect.ValueOf(*reloadState.current), reflect.ValueOf(*config)) {
+		if liveSetting(path, pools) {
+			status.Applied = append(status.Applied, path)
+		}
+	}
+	for _, path := range configChanges("", reflect.ValueOf(*reloadState.started), reflect.ValueOf(*config)) {
+		if !liveSetting(path, pools) {
+			status.RequiresRestart = append(status.RequiresRestart, path)
+		}
+	}
+
+	logLevel.Set(level)
+	if old := ipLimits.Swap(limiter); old != limiter {
+		old.close()
+	}
+	keyQuotas.setConfig(config.Server.Auth.Limits)
+	reloadEngines(config)
+
+	reloadState.current = config
+	return
+}
+
+// reloadEngines applies the timeouts and worker counts in config to the
+// running engines
+func reloadEngines(config *ConfigFile) {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	for _, c := range config.engines() {
+		b, ok := bots[c.displayName()]
+		if !ok {
+			continue
+		}
+
+		b.timeouts.Store(c.timeouts())
+		if err := b.resize("", c.MaxConcurrentUsers); err != nil {
+			slog.Warn("Not resizing workers", "err", err)
+		}
+		for op, n := range c.poolWorkers() {
+			// Starting or stopping a pool waits for a restart
+			if _, ok := b.pools[op]; !ok || n == 0 {
+				continue
+			}
+			if err := b.resize(op, n); err != nil {
+				slog.Warn("Not resizing workers", "err", err)
+			}
+		}
+	}
+}
+
+func logReload(status ReloadStatus, err error) {
+	if err != nil {
+		slog.Error("Failed to reload config", "err", err)
+		return
+	}
+	slog.Info("Config reloaded", "applied", status.Applied)
+	if len(status.RequiresRestart) > 0 {
+		slog.Warn("Config changes need a restart to take effect", "settings", status.RequiresRestart)
+	}
+}
+
+func handleSighup() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	for range sighup {
+		slog.Info("Received SIGHUP, reloading config")
+		logReload(reloadConfig())
+	}
+}
+
+func serveReloadConfig(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "POST") != nil {
+		return
+	}
+	admin, ok := authorizeAdmin(w, r, permConfig)
+	if !ok {
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin request to reload config", "ip", getIP(r), "admin", admin)
+	status, err := reloadConfig()
+	logReload(status, err)
+	if err != nil {
+		httpError(w, "Failed to reload config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, status)
+}
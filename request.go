@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxBodySize = 64 * 1024
+
+type SolveRequest struct {
+	Word string `json:"word"`
+}
+
+type CoachRequest struct {
+	Word     string     `json:"word"`
+	Guesses  []string   `json:"guesses"`
+	Feedback []Feedback `json:"feedback"`
+}
+
+func decodeBody(r *http.Request, v any) error {
+	d := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return fmt.Errorf("Invalid request body: %v", err)
+	}
+	return nil
+}
+
+func parseSolveRequest(r *http.Request) (req SolveRequest, err error) {
+	if r.Method == "POST" {
+		err = decodeBody(r, &req)
+	} else {
+		req.Word = r.Form.Get("w")
+	}
+	if err == nil {
+		err = req.validate(r.Context())
+	}
+	return
+}
+
+func (req SolveRequest) validate(ctx context.Context) error {
+	if !wordValidFor(ctx, req.Word) {
+		return errors.New("Invalid word")
+	}
+	return nil
+}
+
+func parseCoachRequest(r *http.Request) (req CoachRequest, err error) {
+	if r.Method == "POST" {
+		if err = decodeBody(r, &req); err != nil {
+			return
+		}
+		for i, f := range req.Feedback {
+			req.Feedback[i] = Feedback{Word: foldWord(f.Word), Colors: strings.ToLower(f.Colors)}
+		}
+	} else {
+		req.Word = r.Form.Get("w")
+		guesses := r.Form.Get("guess")
+		switch {
+		case req.Word == "":
+			if req.Feedback, err = parseFeedback(guesses); err != nil {
+				return
+			}
+		case guesses != "":
+			req.Guesses = strings.Split(guesses, ",")
+		}
+	}
+
+	err = req.validate(r.Context())
+	return
+}
+
+func (req CoachRequest) validate(ctx context.Context) error {
+	if req.Word == "" {
+		if len(req.Feedback) == 0 {
+			return errors.New("Expected guess")
+		}
+		for _, f := range req.Feedback {
+			if !wordValidFor(ctx, f.Word) || !colorsValid(f.Word, f.Colors) || !sameLength(f.Word, req.Feedback[0].Word) {
+				return fmt.Errorf("invalid guess %q", f.String())
+			}
+		}
+		return nil
+	}
+
+	if !wordValidFor(ctx, req.Word) {
+		return errors.New("Invalid target word")
+	}
+	if len(req.Guesses) == 0 {
+		return errors.New("Expected guess")
+	}
+	for _, g := range req.Guesses {
+		if !wordValid(g) || !sameLength(g, req.Word) {
+			return errors.New("Invalid word")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type requestIDKey struct{}
+
+// validRequestID accepts IDs of up to 128 visible ASCII characters, so a
+// client can't inject anything odd into our logs
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID(incoming string) string {
+	if validRequestID(incoming) {
+		return incoming
+	}
+	return uuid.New().String()
+}
+
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDs takes the request ID from the X-Request-ID header, or generates
+// one, and returns it in the response
+func requestIDs(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := newRequestID(r.Header.Get("X-Request-ID"))
+		w.Header().Set("X-Request-ID", id)
+		h.ServeHTTP(w, r.WithContext(withRequestID(r.Context(), id)))
+	})
+}
+
+// grpcRequestID does the same for the x-request-id metadata of gRPC calls
+func grpcRequestID(ctx context.Context) context.Context {
+	var incoming string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			incoming = v[0]
+		}
+	}
+
+	id := newRequestID(incoming)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+	return withRequestID(ctx, id)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// credential resolves RunAs, "user" or "user:group", to the credential the
+// engine is run with. The group defaults to the user's primary group
+func (config BotConfig) credential() (*syscall.Credential, error) {
+	if config.RunAs == "" {
+		return nil, nil
+	}
+
+	name, group, _ := strings.Cut(config.RunAs, ":")
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	gid := u.Gid
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		gid = g.Gid
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	g, err := strconv.ParseUint(gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	if uid == 0 || g == 0 {
+		return nil, fmt.Errorf("run_as %q must not be root", config.RunAs)
+	}
+	if euid := os.Geteuid(); uint64(euid) != uid && !canSetuid() {
+		return nil, fmt.Errorf("server needs CAP_SETUID and CAP_SETGID to run the engine as %q", config.RunAs)
+	}
+
+	// An empty Groups drops the server's supplementary groups
+	cred := &syscall.Credential{Uid: uint32(uid), Gid: uint32(g), Groups: []uint32{}}
+	if err := permits(cred, config.ExecPath, 0o1); err != nil {
+		return nil, err
+	}
+	if config.IndexPath != "" {
+		if err := permits(cred, config.IndexPath, 0o4); err != nil {
+			return nil, err
+		}
+	}
+	return cred, nil
+}
+
+// canSetuid reports whether the server has CAP_SETUID and CAP_SETGID, which
+// root has unless they were dropped
+func canSetuid() bool {
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return os.Geteuid() == 0
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if hex, ok := strings.CutPrefix(line, "CapEff:"); ok {
+			caps, err := strconv.ParseUint(strings.TrimSpace(hex), 16, 64)
+			const setgid, setuid = 1 << 6, 1 << 7
+			return err == nil && caps&setgid != 0 && caps&setuid != 0
+		}
+	}
+	return os.Geteuid() == 0
+}
+
+// permits checks the permission bits of path grant cred perm, 1 for execute
+// or 4 for read
+func permits(cred *syscall.Credential, path string, perm os.FileMode) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	st := info.Sys().(*syscall.Stat_t)
+
+	m := info.Mode().Perm()
+	switch {
+	case st.Uid == cred.Uid:
+		m >>= 6
+	case st.Gid == cred.Gid:
+		m >>= 3
+	}
+	if m&perm == 0 {
+		return fmt.Errorf("%s is not accessible to uid %d, gid %d", path, cred.Uid, cred.Gid)
+	}
+	return nil
+}
+
+// command is the engine invoked with args, in its environment, as the
+// configured user and in the sandbox if enabled
+func (b *Bot) command(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if b.seccomp != nil {
+		seccomp, err := b.seccompPipe()
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, b.bwrap, b.config.sandboxArgs(3, args)...)
+		cmd.ExtraFiles = []*os.File{seccomp}
+	} else {
+		cmd = exec.CommandContext(ctx, b.config.ExecPath, args...)
+	}
+
+	cmd.Env = b.env()
+	// The engine gets a process group of its own, so that any helpers it
+	// forks are killed along with it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if b.cred != nil {
+		cmd.SysProcAttr.Credential = b.cred
+	}
+	cmd.Cancel = func() error { return terminate(cmd) }
+	cmd.WaitDelay = engineWaitDelay
+	if err := b.limits.prepare(cmd); err != nil {
+		for _, f := range cmd.ExtraFiles {
+			f.Close()
+		}
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// engineWaitDelay is how long Wait waits for the engine's output pipes to be
+// closed after it exits or is terminated, in case a process it forked still
+// holds them
+const engineWaitDelay = 2 * time.Second
+
+// killGroup kills cmd and every process in its process group
+func killGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// start starts cmd, closing the parent's copies of files passed to it
+func (b *Bot) start(cmd *exec.Cmd) error {
+	err := cmd.Start()
+	for _, f := range cmd.ExtraFiles {
+		f.Close()
+	}
+	b.limits.started(cmd)
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// SandboxConfig runs the engine under bubblewrap, with no network, a
+// read-only view of only its executable, index and ReadOnly, a scratch tmpfs
+// at /tmp, and dangerous system calls refused
+type SandboxConfig struct {
+	Enabled   bool     `toml:"enabled"`
+	BwrapPath string   `toml:"bwrap_path" comment:"bwrap from PATH if unset"`
+	ReadOnly  []string `toml:"read_only" comment:"Paths the engine sees read-only besides exec_path and index_path;\n/usr, /lib, /lib64 and /etc/ld.so.cache if empty"`
+}
+
+// The engine's shared libraries, if it has any
+var defaultSandboxReadOnly = []string{"/usr", "/lib", "/lib64", "/etc/ld.so.cache"}
+
+func (config SandboxConfig) bwrap() (string, error) {
+	if config.BwrapPath != "" {
+		return config.BwrapPath, nil
+	}
+	return exec.LookPath("bwrap")
+}
+
+// sandboxArgs are the bubblewrap arguments to run the engine with args, with
+// the seccomp filter passed as file descriptor fd
+func (config BotConfig) sandboxArgs(fd int, args []string) []string {
+	bwrap := []string{
+		"--unshare-all",
+		"--die-with-parent",
+		"--new-session",
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--ro-bind", config.ExecPath, config.ExecPath,
+	}
+	if config.IndexPath != "" {
+		bwrap = append(bwrap, "--ro-bind", config.IndexPath, config.IndexPath)
+	}
+
+	readOnly := config.Sandbox.ReadOnly
+	if readOnly == nil {
+		readOnly = defaultSandboxReadOnly
+	}
+	for _, path := range readOnly {
+		bwrap = append(bwrap, "--ro-bind-try", path, path)
+	}
+
+	bwrap = append(bwrap, "--seccomp", strconv.Itoa(fd), "--", config.ExecPath)
+	return append(bwrap, args...)
+}
+
+// seccompPipe is a pipe to read the seccomp filter from, which start closes
+// again
+func (b *Bot) seccompPipe() (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	// The filter is far smaller than a pipe's buffer, so this doesn't block
+	_, err = w.Write(b.seccomp)
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
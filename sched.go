@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+type QueueFullError struct {
+	RetryAfter time.Duration
+}
+
+func (err QueueFullError) Error() string {
+	return "engine queue full"
+}
+
+type QueueStatus struct {
+	Workers   int   `json:"workers"`
+	Busy      int   `json:"busy"`
+	Depth     int   `json:"depth"`
+	Limit     int   `json:"limit,omitempty"`
+	HighWater int   `json:"highWater"`
+	AvgWait   int64 `json:"avgWait"`
+	Rejected  int64 `json:"rejected"`
+}
+
+type task struct {
+	client   string
+	priority priority
+	run      func() error
+	done     chan error
+	queuedAt time.Time
+}
+
+// fairQueue hands out tasks round-robin across clients, so a client with many
+// queued requests mostly delays itself
+type fairQueue struct {
+	queues map[string][]*task
+	order  []string
+}
+
+func (q *fairQueue) push(t *task) {
+	if q.queues == nil {
+		q.queues = map[string][]*task{}
+	}
+	if len(q.queues[t.client]) == 0 {
+		q.order = append(q.order, t.client)
+	}
+	q.queues[t.client] = append(q.queues[t.client], t)
+}
+
+func (q *fairQueue) pop() *task {
+	if len(q.order) == 0 {
+		return nil
+	}
+
+	client := q.order[0]
+	q.order = q.order[1:]
+
+	queue := q.queues[client]
+	t := queue[0]
+	if len(queue) > 1 {
+		q.queues[client] = queue[1:]
+		q.order = append(q.order, client)
+	} else {
+		delete(q.queues, client)
+	}
+	return t
+}
+
+func (q *fairQueue) remove(t *task) bool {
+	queue := q.queues[t.client]
+	i := slices.Index(queue, t)
+	if i < 0 {
+		return false
+	}
+
+	if len(queue) > 1 {
+		q.queues[t.client] = slices.Delete(queue, i, i+1)
+	} else {
+		delete(q.queues, t.client)
+		q.order = slices.DeleteFunc(q.order, func(c string) bool { return c == t.client })
+	}
+	return true
+}
+
+// scheduler hands engine work to the workers, highest priority first and
+// fairly between clients of the same priority. At most limit tasks, not
+// counting background work, are queued at once
+type scheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	classes [numPriorities]fairQueue
+	closed  bool
+	name    string
+
+	workers int
+	running int
+	busy    int
+
+	limit     int
+	queued    int
+	highWater int
+	wait      time.Duration
+	rejected  int64
+	full      int64
+}
+
+func newScheduler(name string, limit int) *scheduler {
+	s := &scheduler{name: name, limit: limit}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *scheduler) submit(t *task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("engine closed")
+	}
+
+	if t.priority != background {
+		if s.limit > 0 && s.queued >= s.limit {
+			if s.full == 0 {
+				slog.Warn("Queue full, rejecting requests", "queue", s.name, "depth", s.queued, "avg_wait", s.wait)
+			}
+			s.full++
+			s.rejected++
+
+			// Tasks queued now can expect to wait about as long as recent
+			// ones did
+			return QueueFullError{RetryAfter: max(time.Second, s.wait)}
+		}
+		s.queued++
+		s.highWater = max(s.highWater, s.queued)
+	}
+
+	t.queuedAt = time.Now()
+	s.classes[t.priority].push(t)
+	s.cond.Signal()
+	return nil
+}
+
+// dequeued updates the average time spent queued; s.mu must be held
+func (s *scheduler) dequeued(t *task) {
+	if t.priority == background {
+		return
+	}
+	s.queued--
+	s.wait += (time.Since(t.queuedAt) - s.wait) / 8
+
+	if s.full > 0 {
+		slog.Info("Queue accepting requests again", "queue", s.name, "rejected", s.full)
+		s.full = 0
+	}
+}
+
+func (s *scheduler) status() QueueStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return QueueStatus{
+		Workers:   s.workers,
+		Busy:      s.busy,
+		Depth:     s.queued,
+		Limit:     s.limit,
+		HighWater: s.highWater,
+		AvgWait:   s.wait.Milliseconds(),
+		Rejected:  s.rejected,
+	}
+}
+
+// next blocks until there is work, and returns false once the scheduler is
+// closed or the worker calling it is no longer needed
+func (s *scheduler) next() (*task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.closed {
+		if s.running > s.workers {
+			s.running--
+			return nil, false
+		}
+		for i := range s.classes {
+			if t := s.classes[i].pop(); t != nil {
+				s.dequeued(t)
+				s.busy++
+				return t, true
+			}
+		}
+		s.cond.Wait()
+	}
+	return nil, false
+}
+
+// remove takes t out of the queue, and returns false if a worker has already
+// picked it up
+func (s *scheduler) remove(t *task) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.classes[t.priority].remove(t) {
+		return false
+	}
+	s.dequeued(t)
+	return true
+}
+
+// finished is called by workers after running a task from next
+func (s *scheduler) finished() {
+	s.mu.Lock()
+	s.busy--
+	s.mu.Unlock()
+}
+
+// resize sets the number of workers, returning how many need to be started.
+// Excess workers stop once they finish their current task
+func (s *scheduler) resize(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.workers = n
+	start := max(0, n-s.running)
+	s.running += start
+	s.cond.Broadcast()
+	return start
+}
+
+func (s *scheduler) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+func queueStatuses() map[string]QueueStatus {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	statuses := make(map[string]QueueStatus, len(bots))
+	for name, b := range bots {
+		statuses[name] = b.sched.status()
+		for op, s := range b.pools {
+			statuses[name+"/"+op] = s.status()
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+type ScoreResult struct {
+	Guess  string `json:"guess"`
+	Target string `json:"target"`
+	Colors string `json:"colors"`
+}
+
+func wordColors(guess, target string) string {
+	g, t := []rune(guess), []rune(target)
+	colors := make([]byte, len(g))
+	left := make(map[rune]int, len(t))
+
+	for i, c := range t {
+		if i < len(g) && g[i] == c {
+			colors[i] = 'g'
+		} else {
+			left[c]++
+		}
+	}
+
+	for i, c := range g {
+		switch {
+		case colors[i] == 'g':
+		case left[c] > 0:
+			colors[i] = 'y'
+			left[c]--
+		default:
+			colors[i] = 'b'
+		}
+	}
+
+	return string(colors)
+}
+
+func scoreWord(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	target := foldWord(r.Form.Get("t"))
+	guess := foldWord(r.Form.Get("g"))
+
+	if !wordValidFor(r.Context(), target) {
+		httpError(w, "Invalid target word", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/score", "ip", ip, "param", "t")
+		return
+	}
+
+	if !wordValid(guess) || !sameLength(guess, target) {
+		httpError(w, "Invalid guess", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/score", "ip", ip, "param", "g")
+		return
+	}
+
+	result := ScoreResult{
+		Guess:  guess,
+		Target: target,
+		Colors: wordColors(guess, target),
+	}
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,9 @@
+//go:build !linux || !(amd64 || arm64)
+
+package main
+
+import "errors"
+
+func seccompFilter() ([]byte, error) {
+	return nil, errors.New("the engine sandbox is only supported on Linux on amd64 and arm64")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// secretRef matches a ${NAME} reference to an environment variable
+var secretRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadSecrets reads the secrets that are kept in files, and expands ${NAME}
+// references to environment variables in those that aren't, so no secret has
+// to be in the config file itself
+func (config *ConfigFile) loadSecrets() error {
+	var errs []error
+	for _, s := range []struct {
+		key   string
+		value *string
+		file  string
+	}{
+		{"server.admin_token", &config.Server.AdminToken, config.Server.AdminTokenFile},
+		{"server.oidc.client_secret", &config.Server.OIDC.ClientSecret, config.Server.OIDC.ClientSecretFile},
+		{"daily.secret", &config.Daily.Secret, config.Daily.SecretFile},
+		{"errors.sentry_dsn", &config.Errors.SentryDSN, config.Errors.SentryDSNFile},
+		{"errors.webhook", &config.Errors.Webhook, config.Errors.WebhookFile},
+	} {
+		errs = append(errs, loadSecret(s.key, s.value, s.file))
+	}
+
+	// API keys have no _file variant, as server.auth.key_file is one already
+	for name, key := range config.Server.Auth.APIKeys {
+		key, err := expandSecret("server.auth.api_keys."+name, key)
+		config.Server.Auth.APIKeys[name] = key
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, config.Engine.loadSecrets("engine")...)
+	return errors.Join(errs...)
+}
+
+func (config *BotConfig) loadSecrets(path string) (errs []error) {
+	c := &config.Cache
+	errs = append(errs, loadSecret(path+".cache.redis_password", &c.RedisPassword, c.RedisPasswordFile))
+
+	for i := range config.Backends {
+		errs = append(errs, config.Backends[i].loadSecrets(fmt.Sprintf("%s.backends[%d]", path, i))...)
+	}
+	if config.Canary != nil {
+		errs = append(errs, config.Canary.loadSecrets(path+".canary")...)
+	}
+	if config.Shadow != nil {
+		errs = append(errs, config.Shadow.loadSecrets(path+".shadow")...)
+	}
+	return
+}
+
+// loadSecret sets the secret at key to the contents of file, without the
+// trailing newline, if file is set. Otherwise, it expands references in it
+func loadSecret(key string, value *string, file string) error {
+	if file == "" {
+		var err error
+		*value, err = expandSecret(key, *value)
+		return err
+	}
+	if *value != "" {
+		return fmt.Errorf("%s and %s_file can't both be set", key, key)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("%s_file: %w", key, err)
+	}
+	*value = strings.TrimRight(string(data), "\r\n")
+	return nil
+}
+
+func expandSecret(key, s string) (string, error) {
+	var err error
+	s = secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := secretRef.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%s refers to %s, which isn't set", key, name)
+		}
+		return value
+	})
+	return s, err
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+type CoachSession interface {
+	Guess(f Feedback) (*WordReport, error)
+	Close()
+}
+
+type botSession struct {
+	bot     *Bot
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	decoder *json.Decoder
+	stderr  *tailBuffer
+	mu      sync.Mutex
+}
+
+type replaySession struct {
+	ctx      context.Context
+	eng      Engine
+	word     string
+	guesses  []string
+	feedback []Feedback
+}
+
+type builtinSession struct {
+	engine     *BuiltinEngine
+	target     string
+	candidates []string
+}
+
+func (b *Bot) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	if b.wasm != nil {
+		return &replaySession{ctx: ctx, eng: b, word: word}, nil
+	}
+
+	args, err := sessionArgs(word)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case b.sessions <- struct{}{}:
+	default:
+		return nil, TimeoutError("too many coaching sessions")
+	}
+
+	s, err := b.startSession(args)
+	if err != nil {
+		<-b.sessions
+		return nil, err
+	}
+	return s, nil
+}
+
+func (b *Bot) startSession(args []string) (*botSession, error) {
+	s := &botSession{bot: b}
+
+	p, err := b.stdinProcess()
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if err := json.NewEncoder(p.stdin).Encode(args); err != nil {
+			p.kill()
+			return nil, err
+		}
+		s.cmd, s.stdin, s.stderr = p.cmd, p.stdin, p.stderr
+		s.decoder = json.NewDecoder(io.LimitReader(p.stdout, b.config.maxOutput("session")))
+		return s, nil
+	}
+
+	cmd, err := b.command(context.Background(), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.stderr = &tailBuffer{max: 4096}
+	cmd.Stderr = s.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.start(cmd); err != nil {
+		return nil, err
+	}
+
+	s.cmd, s.stdin = cmd, stdin
+	s.decoder = json.NewDecoder(io.LimitReader(stdout, b.config.maxOutput("session")))
+	return s, nil
+}
+
+func (s *botSession) Guess(f Feedback) (*WordReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := sessionLine(f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintln(s.stdin, line); err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(s.bot.timeouts.Load().coach) * time.Millisecond
+	timer := time.AfterFunc(timeout, func() { killGroup(s.cmd) })
+
+	var report WordReport
+	err = s.decoder.Decode(&report)
+	if !timer.Stop() {
+		return nil, TimeoutError("timeout")
+	}
+	if err != nil {
+		return nil, withStderr(DecodeError{err}, s.stderr)
+	}
+
+	return &report, nil
+}
+
+func (s *botSession) Close() {
+	s.stdin.Close()
+
+	timer := time.AfterFunc(time.Second, func() { killGroup(s.cmd) })
+	s.cmd.Wait()
+	timer.Stop()
+
+	<-s.bot.sessions
+}
+
+func (s *replaySession) Guess(f Feedback) (report *WordReport, err error) {
+	if s.word != "" {
+		s.guesses = append(s.guesses, f.Word)
+		if report, err = s.eng.Coach(s.ctx, s.word, s.guesses); err != nil {
+			s.guesses = s.guesses[:len(s.guesses)-1]
+		}
+		return
+	}
+
+	s.feedback = append(s.feedback, f)
+	if report, err = s.eng.CoachFeedback(s.ctx, s.feedback); err != nil {
+		s.feedback = s.feedback[:len(s.feedback)-1]
+	}
+	return
+}
+
+func (s *replaySession) Close() {
+}
+
+func (e *BuiltinEngine) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	target := foldWord(word)
+	if target != "" {
+		if err := e.checkKnown(target); err != nil {
+			return nil, err
+		}
+	}
+
+	return &builtinSession{engine: e, target: target, candidates: e.words}, nil
+}
+
+func (s *builtinSession) Guess(f Feedback) (*WordReport, error) {
+	guess := foldWord(f.Word)
+
+	var report WordReport
+	if s.target != "" {
+		user := Guess{Word: guess, Score: guessEntropy(guess, s.candidates)}
+		report = s.engine.report(user, s.engine.rank(s.candidates), s.target, s.candidates)
+	} else {
+		report = s.engine.feedbackReport(s.candidates, Feedback{Word: guess, Colors: f.Colors})
+	}
+
+	s.candidates = report.OptionsLeft
+	return &report, nil
+}
+
+func (s *builtinSession) Close() {
+}
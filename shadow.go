@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxShadowDiffs = 10
+
+type ShadowEngine struct {
+	primary   Engine
+	candidate Engine
+	slots     chan struct{}
+
+	logMu   sync.Mutex
+	logFile *os.File
+}
+
+type shadowRecord struct {
+	Time           time.Time       `json:"time"`
+	Op             string          `json:"op"`
+	Args           []string        `json:"args"`
+	Diffs          []string        `json:"diffs"`
+	Primary        json.RawMessage `json:"primary,omitempty"`
+	Candidate      json.RawMessage `json:"candidate,omitempty"`
+	PrimaryError   string          `json:"primaryError,omitempty"`
+	CandidateError string          `json:"candidateError,omitempty"`
+}
+
+func NewShadowEngine(primary, candidate Engine, config BotConfig) (*ShadowEngine, error) {
+	slots := config.MaxConcurrentUsers
+	if slots <= 0 {
+		slots = 1
+	}
+
+	s := &ShadowEngine{
+		primary:   primary,
+		candidate: candidate,
+		slots:     make(chan struct{}, slots),
+	}
+
+	if config.ShadowLog != "" {
+		f, err := os.OpenFile(config.ShadowLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		s.logFile = f
+	}
+
+	return s, nil
+}
+
+func jsonDiff(a, b any, path string, diffs []string) []string {
+	if len(diffs) >= maxShadowDiffs {
+		return diffs
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		for k := range av {
+			diffs = jsonDiff(av[k], bv[k], path+"."+k, diffs)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				diffs = jsonDiff(nil, bv[k], path+"."+k, diffs)
+			}
+		}
+		return diffs
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if len(av) != len(bv) {
+			return append(diffs, fmt.Sprintf("%s: length %d != %d", path, len(av), len(bv)))
+		}
+		for i := range av {
+			diffs = jsonDiff(av[i], bv[i], fmt.Sprintf("%s[%d]", path, i), diffs)
+		}
+		return diffs
+	}
+
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		diffs = append(diffs, fmt.Sprintf("%s: %v != %v", path, a, b))
+	}
+	return diffs
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (s *ShadowEngine) compare(ctx context.Context, op string, args []string, primary any, primaryErr error, candidate any, candidateErr error) {
+	rec := shadowRecord{
+		Time:           time.Now(),
+		Op:             op,
+		Args:           args,
+		PrimaryError:   errString(primaryErr),
+		CandidateError: errString(candidateErr),
+	}
+
+	switch {
+	case primaryErr != nil && candidateErr != nil:
+		return
+	case primaryErr != nil || candidateErr != nil:
+		rec.Diffs = []string{"error mismatch"}
+	}
+
+	if primaryErr == nil {
+		rec.Primary, _ = json.Marshal(primary)
+	}
+	if candidateErr == nil {
+		rec.Candidate, _ = json.Marshal(candidate)
+	}
+
+	if rec.Diffs == nil {
+		var a, b any
+		json.Unmarshal(rec.Primary, &a)
+		json.Unmarshal(rec.Candidate, &b)
+		rec.Diffs = jsonDiff(a, b, "", nil)
+	}
+
+	if len(rec.Diffs) == 0 {
+		return
+	}
+
+	slog.WarnContext(ctx, "Shadow engine disagrees", "op", op, "args", strings.Join(args, " "), "diffs", strings.Join(rec.Diffs, "; "))
+
+	if s.logFile != nil {
+		s.logMu.Lock()
+		defer s.logMu.Unlock()
+		if err := json.NewEncoder(s.logFile).Encode(rec); err != nil {
+			slog.Warn("Failed to write shadow log", "err", err)
+		}
+	}
+}
+
+func shadow[T any](ctx context.Context, s *ShadowEngine, op string, args []string, call func(context.Context, Engine) (T, error)) (T, error) {
+	result, err := call(ctx, s.primary)
+	if errors.Is(err, context.Canceled) {
+		return result, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		go func() {
+			defer func() { <-s.slots }()
+			// The candidate runs on after the client has its response
+			candidate, candidateErr := call(context.WithoutCancel(ctx), s.candidate)
+			s.compare(ctx, op, args, result, err, candidate, candidateErr)
+		}()
+	default:
+	}
+
+	return result, err
+}
+
+func (s *ShadowEngine) Solve(ctx context.Context, word string) ([]WordReport, error) {
+	return shadow(ctx, s, "solve", []string{word}, func(ctx context.Context, e Engine) ([]WordReport, error) {
+		return e.Solve(ctx, word)
+	})
+}
+
+func (s *ShadowEngine) SolveStream(ctx context.Context, word string, emit ReportFunc) error {
+	_, err := shadow(ctx, s, "solve", []string{word}, func(ctx context.Context, e Engine) ([]WordReport, error) {
+		if e != s.primary {
+			return e.Solve(ctx, word)
+		}
+
+		var reports []WordReport
+		err := e.SolveStream(ctx, word, func(turn int, report WordReport) error {
+			reports = append(reports[:turn], report)
+			return emit(turn, report)
+		})
+		return reports, err
+	})
+	return err
+}
+
+func (s *ShadowEngine) Coach(ctx context.Context, word string, guesses []string) (*WordReport, error) {
+	args := append([]string{word}, guesses...)
+	return shadow(ctx, s, "coach", args, func(ctx context.Context, e Engine) (*WordReport, error) {
+		return e.Coach(ctx, word, guesses)
+	})
+}
+
+func (s *ShadowEngine) CoachFeedback(ctx context.Context, feedback []Feedback) (*WordReport, error) {
+	return shadow(ctx, s, "coach", feedbackArgs(feedback), func(ctx context.Context, e Engine) (*WordReport, error) {
+		return e.CoachFeedback(ctx, feedback)
+	})
+}
+
+func (s *ShadowEngine) CoachSession(ctx context.Context, word string) (CoachSession, error) {
+	return s.primary.CoachSession(ctx, word)
+}
+
+func (s *ShadowEngine) Suggest(ctx context.Context, c Constraints) (*Suggestion, error) {
+	return shadow(ctx, s, "suggest", c.args(), func(ctx context.Context, e Engine) (*Suggestion, error) {
+		return e.Suggest(ctx, c)
+	})
+}
+
+func (s *ShadowEngine) WordList(ctx context.Context, list string) ([]string, error) {
+	return shadow(ctx, s, "list", []string{list}, func(ctx context.Context, e Engine) ([]string, error) {
+		return e.WordList(ctx, list)
+	})
+}
+
+func (s *ShadowEngine) Close() {
+	s.primary.Close()
+	s.candidate.Close()
+	if s.logFile != nil {
+		s.logFile.Close()
+	}
+}
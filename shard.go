@@ -0,0 +1,123 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const maxSolveTurns = 20
+
+// run is exec, except that solves are split across solve_shards engine
+// processes if configured
+func (b *Bot) run(ctx context.Context, timeout int, v any, args ...string) error {
+	if target, ok := solveTarget(args); ok && b.config.SolveShards > 1 {
+		return b.solveSharded(ctx, timeout, v, target)
+	}
+	return b.exec(ctx, timeout, v, args...)
+}
+
+func (b *Bot) answers() ([]string, error) {
+	b.answersOnce.Do(func() {
+		b.answerList, b.answersErr = b.WordList(context.Background(), "answers")
+	})
+	return b.answerList, b.answersErr
+}
+
+// rankSharded has each shard rank its slice of the guess list, and merges the
+// results
+func (b *Bot) rankSharded(ctx context.Context, timeout int, target string, guesses []string) ([]Guess, error) {
+	n := b.config.SolveShards
+	ranked := make([][]Guess, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			args, err := rankArgs(target, i, n, guesses)
+			if err == nil {
+				err = b.exec(ctx, timeout, &ranked[i], args...)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var merged []Guess
+	size := 0
+	for _, r := range ranked {
+		merged = append(merged, r...)
+		size = max(size, len(r))
+	}
+	slices.SortStableFunc(merged, func(a, b Guess) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Word, b.Word))
+	})
+	return merged[:size], nil
+}
+
+func (b *Bot) solveSharded(ctx context.Context, timeout int, v any, target string) error {
+	answers, err := b.answers()
+	if err != nil {
+		return err
+	}
+	// The answers hold words of every length, but only those of the target's
+	// can be left
+	candidates := ofLength(answers, utf8.RuneCountInString(target))
+
+	var reports []WordReport
+	var guesses []string
+	for turn := 0; turn < maxSolveTurns; turn++ {
+		best, err := b.rankSharded(ctx, timeout, target, guesses)
+		if err != nil {
+			return err
+		}
+		if len(best) == 0 {
+			return errors.New("engine ranked no guesses")
+		}
+
+		guess := best[0]
+		colors := wordColors(guess.Word, target)
+		left := filterWords(candidates, guess.Word, colors)
+		report := WordReport{
+			User:        guess,
+			Best:        best,
+			OptionsLeft: left,
+			Eliminated:  int32(len(candidates) - len(left)),
+			Colors:      colors,
+		}
+
+		if s, ok := v.(*reportStream); ok && s.emit != nil {
+			if err := s.emit(turn, report); err != nil {
+				return StreamError{err}
+			}
+		} else {
+			reports = append(reports, report)
+		}
+
+		if strings.Trim(colors, "g") == "" {
+			switch v := v.(type) {
+			case *reportStream:
+				v.reports = reports
+			case *[]WordReport:
+				*v = reports
+			}
+			return nil
+		}
+
+		guesses = append(guesses, guess.Word)
+		candidates = left
+	}
+
+	return fmt.Errorf("engine did not solve %s in %d turns", target, maxSolveTurns)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Turn struct {
+	Guess      string  `json:"guess"`
+	Score      float32 `json:"score"`
+	Colors     string  `json:"colors"`
+	Candidates int     `json:"candidates"`
+	Eliminated int32   `json:"eliminated"`
+}
+
+type Transcript struct {
+	Target  string `json:"target"`
+	Turns   []Turn `json:"turns"`
+	Guesses int    `json:"guesses"`
+	Solved  bool   `json:"solved"`
+}
+
+func transcript(target string, reports []WordReport) Transcript {
+	t := Transcript{Target: target, Turns: make([]Turn, len(reports))}
+	for i, r := range reports {
+		t.Turns[i] = Turn{
+			Guess:      r.User.Word,
+			Score:      r.User.Score,
+			Colors:     r.Colors,
+			Candidates: len(r.OptionsLeft),
+			Eliminated: r.Eliminated,
+		}
+	}
+
+	t.Guesses = len(t.Turns)
+	if t.Guesses > 0 {
+		last := t.Turns[t.Guesses-1]
+		t.Solved = last.Colors == strings.Repeat("g", len(last.Colors))
+	}
+	return t
+}
+
+func simulateWord(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	word := r.Form.Get("w")
+
+	if !wordValidFor(r.Context(), word) {
+		httpError(w, "Invalid word", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/simulate", "ip", ip, "param", "w")
+		return
+	}
+
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/simulate", "ip", ip, "word", word, "engine", name)
+	start := time.Now()
+
+	data, err := eng.Solve(requestContext(r), word)
+	router.record(name, err)
+	if err != nil {
+		internalError(w, r, err)
+	} else {
+		writeJSON(w, r, transcript(foldWord(word), data))
+	}
+
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/simulate", "duration", time.Since(start))
+}
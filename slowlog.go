@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// slowLog records engine runs that took longer than a threshold, to find the
+// words that are pathological for an engine
+type slowLog struct {
+	threshold time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+type slowRecord struct {
+	Time      time.Time `json:"time"`
+	UUID      string    `json:"uuid,omitempty"`
+	Engine    string    `json:"engine"`
+	Args      []string  `json:"args"`
+	QueueWait int64     `json:"queueWait"`
+	Exec      int64     `json:"exec"`
+	Output    int64     `json:"output"`
+	ExitCode  int       `json:"exitCode"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func newSlowLog(config BotConfig) (*slowLog, error) {
+	if config.SlowThreshold <= 0 {
+		return nil, nil
+	}
+
+	l := &slowLog{threshold: time.Duration(config.SlowThreshold) * time.Millisecond}
+	if config.SlowLog != "" {
+		f, err := os.OpenFile(config.SlowLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		l.file = f
+	}
+	return l, nil
+}
+
+func (l *slowLog) record(ctx context.Context, engine string, args []string, queueWait, exec time.Duration, res execResult, err error) {
+	if l == nil || exec < l.threshold {
+		return
+	}
+
+	rec := slowRecord{
+		Time:      time.Now().UTC(),
+		UUID:      requestIDFrom(ctx),
+		Engine:    engine,
+		Args:      args,
+		QueueWait: queueWait.Milliseconds(),
+		Exec:      exec.Milliseconds(),
+		Output:    res.output,
+		ExitCode:  res.exitCode,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	slog.WarnContext(ctx, "Slow engine run", "engine", engine, "args", strings.Join(args, " "),
+		"queue_wait", queueWait, "duration", exec, "output", res.output, "exit_code", res.exitCode, "err", err)
+
+	if l.file != nil {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if err := json.NewEncoder(l.file).Encode(rec); err != nil {
+			slog.Warn("Failed to write slow log", "err", err)
+		}
+	}
+}
+
+func (l *slowLog) Close() {
+	if l != nil && l.file != nil {
+		l.file.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// engineKillGrace is how long the engine has to exit after SIGTERM, before
+// it is sent SIGKILL
+const engineKillGrace = time.Second
+
+// StalledError means the engine stopped writing output without exiting
+type StalledError struct {
+	Idle time.Duration
+}
+
+func (err StalledError) Error() string {
+	return fmt.Sprintf("engine produced no output for %v", err.Idle)
+}
+
+// stallReader fails reads from the engine's stdout that wait longer than
+// timeout for output
+type stallReader struct {
+	f       *os.File
+	timeout time.Duration
+}
+
+func (r stallReader) Read(p []byte) (int, error) {
+	r.f.SetReadDeadline(time.Now().Add(r.timeout))
+	n, err := r.f.Read(p)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = StalledError{r.timeout}
+	}
+	return n, err
+}
+
+// watchStalls wraps the engine's stdout in a stallReader if a stall timeout
+// is configured
+func (b *Bot) watchStalls(stdout io.Reader) io.Reader {
+	f, ok := stdout.(*os.File)
+	stall := b.timeouts.Load().stall
+	if !ok || stall <= 0 {
+		return stdout
+	}
+	return stallReader{f: f, timeout: time.Duration(stall) * time.Millisecond}
+}
+
+// terminate asks the engine's process group to exit with SIGTERM, and kills
+// it if it is still running engineKillGrace later
+func terminate(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	pid := cmd.Process.Pid
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		return cmd.Process.Kill()
+	}
+
+	time.AfterFunc(engineKillGrace, func() {
+		// Once Wait has returned, the process group may not exist anymore
+		if cmd.Process.Signal(syscall.Signal(0)) != os.ErrProcessDone {
+			syscall.Kill(-pid, syscall.SIGKILL)
+		}
+	})
+	return nil
+}
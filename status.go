@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+var startTime = time.Now()
+
+type ServerStatus struct {
+	Uptime   int64                    `json:"uptime"`
+	Engines  map[string]EngineStats   `json:"engines"`
+	Backends map[string]BackendStatus `json:"backends"`
+	Breakers map[string]BreakerStatus `json:"breakers"`
+	Caches   map[string]CacheStatus   `json:"caches"`
+	Queues   map[string]QueueStatus   `json:"queues"`
+	Latency  LatencyStatus            `json:"latency"`
+}
+
+// BackendStatus identifies what a local engine runs, and how it last failed
+type BackendStatus struct {
+	ExecPath      string     `json:"execPath,omitempty"`
+	ExecChecksum  string     `json:"execChecksum,omitempty"`
+	WasmPath      string     `json:"wasmPath,omitempty"`
+	WasmChecksum  string     `json:"wasmChecksum,omitempty"`
+	IndexPath     string     `json:"indexPath,omitempty"`
+	IndexChecksum string     `json:"indexChecksum,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+type engineFiles struct {
+	exec, wasm, index string
+	// version changes with any of the files
+	version string
+}
+
+func fileChecksum(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashFiles checksums the engine's files in the background, since indexes
+// can be large
+func (b *Bot) hashFiles() *engineFiles {
+	files := &engineFiles{
+		exec:  fileChecksum(b.config.ExecPath),
+		wasm:  fileChecksum(b.config.WasmPath),
+		index: fileChecksum(b.config.IndexPath),
+	}
+	sum := sha256.Sum256([]byte(files.exec + files.wasm + files.index))
+	files.version = hex.EncodeToString(sum[:8])
+	b.files.Store(files)
+	return files
+}
+
+func (b *Bot) recordError(err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	b.lastErr = err.Error()
+	b.lastErrAt = time.Now().UTC()
+}
+
+func (b *Bot) status() BackendStatus {
+	s := BackendStatus{
+		ExecPath:  b.config.ExecPath,
+		WasmPath:  b.config.WasmPath,
+		IndexPath: b.config.IndexPath,
+	}
+	if files := b.files.Load(); files != nil {
+		s.ExecChecksum = files.exec
+		s.WasmChecksum = files.wasm
+		s.IndexChecksum = files.index
+	}
+
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	if b.lastErr != "" {
+		at := b.lastErrAt
+		s.LastError = b.lastErr
+		s.LastErrorAt = &at
+	}
+	return s
+}
+
+func backendStatuses() map[string]BackendStatus {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	statuses := make(map[string]BackendStatus, len(bots))
+	for name, b := range bots {
+		statuses[name] = b.status()
+	}
+	return statuses
+}
+
+func serveStatus(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	status := ServerStatus{
+		Uptime:   int64(time.Since(startTime).Seconds()),
+		Engines:  router.Stats(),
+		Backends: backendStatuses(),
+		Breakers: breakerStatuses(),
+		Caches:   cacheStatuses(),
+		Queues:   queueStatuses(),
+		Latency:  latencyStatus(),
+	}
+	writeJSON(w, r, status)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ReportFunc func(turn int, report WordReport) error
+
+type streamTarget interface {
+	decodeStream(d *json.Decoder) error
+}
+
+type StreamError struct {
+	Err error
+}
+
+func (err StreamError) Error() string {
+	return fmt.Sprintf("streaming response: %v", err.Err)
+}
+
+func (err StreamError) Unwrap() error {
+	return err.Err
+}
+
+type reportStream struct {
+	reports []WordReport
+	emit    ReportFunc
+}
+
+func decodeInto(d *json.Decoder, v any) error {
+	if s, ok := v.(streamTarget); ok {
+		return s.decodeStream(d)
+	}
+	return d.Decode(v)
+}
+
+// Reports are only kept if there is no emit function to hand them to
+func (s *reportStream) decodeStream(d *json.Decoder) error {
+	s.reports = nil
+	turn := 0
+
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expected array of reports, got %v", tok)
+	}
+
+	for d.More() {
+		var report WordReport
+		if err := d.Decode(&report); err != nil {
+			return err
+		}
+
+		if s.emit == nil {
+			s.reports = append(s.reports, report)
+		} else if err := s.emit(turn, report); err != nil {
+			return StreamError{err}
+		}
+		turn++
+	}
+
+	_, err = d.Token()
+	return err
+}
+
+func emitAll(reports []WordReport, emit ReportFunc) error {
+	if emit == nil {
+		return nil
+	}
+
+	for i, r := range reports {
+		if err := emit(i, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeEvent(w http.ResponseWriter, event string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+func wantsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func wantsStream(r *http.Request) bool {
+	return wantsEventStream(r) || r.Form.Get("format") == "ndjson"
+}
+
+func writeLine(w http.ResponseWriter, data any) error {
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+func streamSolve(w http.ResponseWriter, r *http.Request, id string, word string, eng Engine) error {
+	contentType := "application/x-ndjson"
+	write := func(event string, data any) error {
+		return writeLine(w, data)
+	}
+
+	keepOpen(w)
+	sse := wantsEventStream(r)
+	if sse {
+		contentType = "text/event-stream"
+		write = func(event string, data any) error {
+			return writeEvent(w, event, data)
+		}
+	}
+
+	var reports []WordReport
+	sent := 0
+	emit := func(turn int, report WordReport) error {
+		// Retries and failover start over from the first turn
+		if sse {
+			reports = append(reports[:turn], report)
+		}
+		if turn < sent {
+			return nil
+		}
+
+		if sent == 0 {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		sent++
+		return write("report", report)
+	}
+
+	err := eng.SolveStream(requestContext(r), word, emit)
+	if err != nil {
+		if sent == 0 {
+			internalError(w, r, err)
+		} else {
+			logFailure(r.Context(), err)
+			if reportable(err) {
+				reportError(r.Context(), r, err, nil)
+			}
+			write("error", map[string]string{"error": err.Error(), "uuid": id})
+		}
+		return err
+	}
+
+	if sse {
+		return write("summary", transcript(foldWord(word), reports))
+	}
+	return nil
+}
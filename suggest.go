@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type YellowLetter struct {
+	Letter   rune
+	Position int
+}
+
+type Constraints struct {
+	Green  string
+	Yellow []YellowLetter
+	Gray   string
+}
+
+type Suggestion struct {
+	Best        []Guess  `json:"best"`
+	OptionsLeft []string `json:"optionsLeft"`
+}
+
+func parseConstraints(ctx context.Context, form url.Values) (c Constraints, err error) {
+	c.Green = foldWord(form.Get("green"))
+	if c.Green == "" {
+		c.Green = strings.Repeat("_", wordLength(ctx))
+	}
+
+	green := []rune(c.Green)
+	switch n := lengthFrom(ctx); {
+	case n != 0 && len(green) != n:
+		return c, fmt.Errorf("green pattern must have %d letters", n)
+	case !slices.Contains(lengthsFor(dictionaryFrom(ctx)), len(green)):
+		return c, fmt.Errorf("green pattern can't have %d letters", len(green))
+	}
+	for _, r := range green {
+		if r != '_' && !letterValid(r) {
+			return c, errors.New("green pattern must consist of letters and underscores")
+		}
+	}
+
+	if yellow := form.Get("yellow"); yellow != "" {
+		for _, y := range strings.Split(foldWord(yellow), ",") {
+			letter, pos, ok := strings.Cut(y, ":")
+			l := []rune(letter)
+			if !ok || len(l) != 1 || !letterValid(l[0]) {
+				return c, fmt.Errorf("invalid yellow letter %q", y)
+			}
+
+			p, err := strconv.Atoi(pos)
+			if err != nil || p < 1 || p > len(green) {
+				return c, fmt.Errorf("invalid yellow letter position %q", y)
+			}
+
+			c.Yellow = append(c.Yellow, YellowLetter{Letter: l[0], Position: p})
+		}
+	}
+
+	c.Gray = foldWord(form.Get("gray"))
+	for _, r := range c.Gray {
+		if !letterValid(r) {
+			return c, errors.New("gray letters must be letters")
+		}
+	}
+
+	return c, nil
+}
+
+func (c Constraints) yellowString() string {
+	yellow := make([]string, len(c.Yellow))
+	for i, y := range c.Yellow {
+		yellow[i] = fmt.Sprintf("%c:%d", y.Letter, y.Position)
+	}
+	return strings.Join(yellow, ",")
+}
+
+func (c Constraints) args() []string {
+	return []string{"--green", c.Green, "--yellow", c.yellowString(), "--gray", c.Gray}
+}
+
+func (c Constraints) known(r rune) bool {
+	if strings.ContainsRune(c.Green, r) {
+		return true
+	}
+	for _, y := range c.Yellow {
+		if y.Letter == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraints) match(word string) bool {
+	w := []rune(word)
+	for i, r := range []rune(c.Green) {
+		if r != '_' && (i >= len(w) || w[i] != r) {
+			return false
+		}
+	}
+
+	for _, y := range c.Yellow {
+		if y.Position <= len(w) && w[y.Position-1] == y.Letter {
+			return false
+		}
+		if !strings.ContainsRune(word, y.Letter) {
+			return false
+		}
+	}
+
+	for _, r := range c.Gray {
+		if strings.ContainsRune(word, r) && !c.known(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func suggestWords(w http.ResponseWriter, r *http.Request) {
+	if enforceMethod(w, r, "GET") != nil {
+		return
+	}
+
+	ip := getIP(r)
+
+	r.ParseForm()
+	c, err := parseConstraints(r.Context(), r.Form)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/suggest", "ip", ip, "err", err)
+		return
+	}
+
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/suggest", "ip", ip, "green", c.Green, "yellow", c.yellowString(), "gray", c.Gray, "engine", name)
+	start := time.Now()
+
+	data, err := eng.Suggest(requestContext(r), c)
+	router.record(name, err)
+	if err != nil {
+		internalError(w, r, err)
+	} else {
+		writeResponse(w, r, data)
+	}
+
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/suggest", "duration", time.Since(start))
+}
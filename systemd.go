@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// activated holds the sockets systemd passed for socket activation. Those
+// named grpc or debug with FileDescriptorName= serve gRPC and the debug
+// endpoints, and any others serve the API. It isn't changed after
+// setupSystemd
+var activated = map[string][]net.Listener{}
+
+// activatedNames has the names of the sockets in activated, which listeners
+// in [[server.listeners]] can claim with name
+var activatedNames = map[net.Listener]string{}
+
+// notifySocket is where systemd listens for sd_notify messages, if it
+// started the server as a Type=notify service
+var notifySocket string
+
+// setupSystemd takes the sockets and notification socket systemd passed, if
+// any, and starts sending it watchdog keep-alives if the unit has
+// WatchdogSec= set. It clears the variables involved, so engine processes
+// don't take them for their own
+func setupSystemd() error {
+	notifySocket = os.Getenv("NOTIFY_SOCKET")
+	forUs := func(name string) bool {
+		pid, err := strconv.Atoi(os.Getenv(name))
+		return err == nil && pid == os.Getpid()
+	}
+	listenFds, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if !forUs("LISTEN_PID") {
+		listenFds = 0
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	watchdog, _ := strconv.Atoi(os.Getenv("WATCHDOG_USEC"))
+	if os.Getenv("WATCHDOG_PID") != "" && !forUs("WATCHDOG_PID") {
+		watchdog = 0
+	}
+	for _, name := range []string{"NOTIFY_SOCKET", "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES", "WATCHDOG_USEC", "WATCHDOG_PID"} {
+		os.Unsetenv(name)
+	}
+
+	// Passed file descriptors start after stdin, stdout and stderr
+	for i := range listenFds {
+		fd := 3 + i
+		syscall.CloseOnExec(fd)
+
+		name := "api"
+		if i < len(names) && (names[i] == "grpc" || names[i] == "debug") {
+			name = names[i]
+		}
+		fdName := name
+		if i < len(names) {
+			fdName = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		activated[name] = append(activated[name], l)
+		activatedNames[l] = fdName
+		slog.Info("Using socket passed by systemd", "socket", fdName, "addr", l.Addr())
+	}
+
+	if watchdog > 0 && notifySocket != "" {
+		go func() {
+			for range time.Tick(time.Duration(watchdog) * time.Microsecond / 2) {
+				sdNotify("WATCHDOG=1")
+			}
+		}()
+	}
+	return nil
+}
+
+// activatedListener is the socket systemd passed for gRPC or the debug
+// endpoints, or nil
+func activatedListener(name string) net.Listener {
+	if ls := activated[name]; len(ls) > 0 {
+		return ls[0]
+	}
+	return nil
+}
+
+// sdNotify tells systemd about the server's state, as sd_notify does
+func sdNotify(state string) {
+	if notifySocket == "" {
+		return
+	}
+
+	addr := notifySocket
+	if addr[0] == '@' {
+		// An abstract socket
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		slog.Warn("Failed to notify systemd", "err", err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		slog.Warn("Failed to notify systemd", "err", err)
+	}
+}
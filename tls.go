@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certCheckInterval is how often the certificate files are checked for
+// changes
+const certCheckInterval = 10 * time.Second
+
+// certReloader serves the certificate in certFile and keyFile, reloading it
+// when either file changes, so renewed certificates are picked up without a
+// restart
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	go c.watch()
+	return c, nil
+}
+
+// lastModified is the latest modification time of the certificate files
+func (c *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (c *certReloader) load() error {
+	modTime, err := c.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert, c.modTime = &cert, modTime
+	return nil
+}
+
+func (c *certReloader) watch() {
+	for range time.Tick(certCheckInterval) {
+		modTime, err := c.lastModified()
+		c.mu.RLock()
+		changed := err == nil && !modTime.Equal(c.modTime)
+		c.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		// Keep serving the old certificate if the new one is only half
+		// written, and try again next time
+		if err := c.load(); err != nil {
+			slog.Warn("Failed to reload TLS certificate", "cert", c.certFile, "err", err)
+			continue
+		}
+		slog.Info("Reloaded TLS certificate", "cert", c.certFile)
+	}
+}
+
+func (c *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// ACMEConfig obtains and renews certificates for Domains from Let's Encrypt,
+// or another ACME directory
+type ACMEConfig struct {
+	Domains  []string `toml:"domains"`
+	CacheDir string   `toml:"cache_dir"`
+	Email    string   `toml:"email"`
+	// HTTPAddr serves HTTP-01 challenges, redirecting other requests to
+	// HTTPS
+	HTTPAddr     string `toml:"http_addr" comment:"Answers HTTP-01 challenges and redirects everything else to HTTPS"`
+	DirectoryURL string `toml:"directory_url" comment:"Use another ACME directory, such as Let's Encrypt's staging environment"`
+}
+
+func acmeTLS(config ACMEConfig, httpConfig HTTPConfig) (*tls.Config, error) {
+	if config.CacheDir == "" {
+		return nil, errors.New("acme.cache_dir must be set, to not request new certificates on every start")
+	}
+	if config.HTTPAddr == "" {
+		config.HTTPAddr = ":80"
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(config.Domains...),
+		Cache:      autocert.DirCache(config.CacheDir),
+		Email:      config.Email,
+	}
+	if config.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: config.DirectoryURL}
+	}
+
+	go mustServe(func() error {
+		slog.Info("Serving ACME HTTP-01 challenges", "addr", config.HTTPAddr, "domains", config.Domains)
+		return listenAndServe(config.HTTPAddr, m.HTTPHandler(nil), nil, httpConfig)
+	})
+
+	tlsConfig := m.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	return tlsConfig, nil
+}
+
+// serverTLS is the TLS configuration of the HTTP and gRPC listeners, or nil
+// to serve them in plain text
+func serverTLS(config ServerConfig) (*tls.Config, error) {
+	if len(config.ACME.Domains) > 0 {
+		if config.TLSCert != "" || config.TLSKey != "" {
+			return nil, errors.New("tls_cert and tls_key can't be used with acme")
+		}
+		return acmeTLS(config.ACME, config.HTTP)
+	}
+
+	if config.TLSCert == "" && config.TLSKey == "" {
+		return nil, nil
+	}
+	if config.TLSCert == "" || config.TLSKey == "" {
+		return nil, errors.New("tls_cert and tls_key must be set together")
+	}
+
+	certs, err := newCertReloader(config.TLSCert, config.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.getCertificate,
+	}, nil
+}
+
+func listenAndServe(addr string, handler http.Handler, tlsConfig *tls.Config, config HTTPConfig) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serve(l, handler, tlsConfig, config)
+}
+
+func serve(l net.Listener, handler http.Handler, tlsConfig *tls.Config, config HTTPConfig) error {
+	srv := newHTTPServer(l.Addr().String(), handler, tlsConfig, config)
+	servers.Lock()
+	servers.http = append(servers.http, srv)
+	servers.Unlock()
+
+	var err error
+	if tlsConfig == nil {
+		err = srv.Serve(l)
+	} else {
+		err = srv.ServeTLS(l, "", "")
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Defaults for engine settings that would leave the engine unusable if they
+// were zero
+const (
+	defaultConcurrentUsers = 2
+	defaultSolveTimeout    = 5000
+	defaultCoachTimeout    = 4000
+)
+
+// check fills in defaults and validates the config, naming the TOML key of
+// every problem it finds
+func (config *ConfigFile) check() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if p := config.Server.Port; p < 1 || p > 65535 {
+		fail("server.port must be between 1 and 65535, got %d", p)
+	}
+	if p := config.Server.GrpcPort; p < 0 || p > 65535 {
+		fail("server.grpc_port must be between 1 and 65535, or 0 to disable gRPC, got %d", p)
+	}
+
+	if _, err := socketMode(config.Server.SocketMode); err != nil {
+		fail("server.%v", err)
+	}
+	if config.Server.ShutdownDelay < 0 {
+		fail("server.shutdown_delay must not be negative, got %d", config.Server.ShutdownDelay)
+	}
+
+	hasTLS := config.Server.TLSCert != "" || len(config.Server.ACME.Domains) > 0
+	if config.Server.HTTP3Addr != "" && !hasTLS {
+		fail("server.http3_addr needs tls_cert and tls_key, or acme")
+	}
+	for i, l := range config.Server.Listeners {
+		if l.HTTP3Addr != "" && (l.Plaintext || !hasTLS) {
+			fail("server.listeners[%d].http3_addr needs TLS, which the listener doesn't use", i)
+		}
+		if l.Name == "" && l.Listen == "" {
+			fail("server.listeners[%d] needs listen or name", i)
+		}
+		switch l.Endpoints {
+		case "", "all", "api", "admin":
+		default:
+			fail("server.listeners[%d].endpoints must be all, api or admin, got %q", i, l.Endpoints)
+		}
+		if _, err := socketMode(l.SocketMode); err != nil {
+			fail("server.listeners[%d].%v", i, err)
+		}
+	}
+
+	hc := &config.Server.HTTP
+	if hc.ReadHeaderTimeout == 0 {
+		hc.ReadHeaderTimeout = defaultReadHeaderTimeout
+	}
+	if hc.IdleTimeout == 0 {
+		hc.IdleTimeout = defaultIdleTimeout
+	}
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"read_header_timeout", hc.ReadHeaderTimeout},
+		{"read_timeout", hc.ReadTimeout},
+		{"write_timeout", hc.WriteTimeout},
+		{"idle_timeout", hc.IdleTimeout},
+		{"max_header_bytes", hc.MaxHeaderBytes},
+	} {
+		if setting.value < 0 {
+			fail("server.http.%s must not be negative, got %d", setting.key, setting.value)
+		}
+	}
+
+	if cors := config.Server.CORS; cors.AllowCredentials && slices.Contains(cors.AllowedOrigins, "*") {
+		fail("server.cors.allow_credentials can't be combined with allowed_origins \"*\"; list the origins instead")
+	}
+
+	if _, err := parseLogLevel(config.Log.Level); err != nil {
+		fail("log.level must be one of debug, info, warn and error, got %q", config.Log.Level)
+	}
+	if f := strings.ToLower(config.Log.Format); f != "" && f != "text" && f != "json" {
+		fail("log.format must be text or json, got %q", config.Log.Format)
+	}
+
+	if config.Daily.Enabled && config.Daily.Secret == "" && config.Daily.SecretFile == "" {
+		fail("daily.secret or daily.secret_file is required, unless daily.enabled is false")
+	}
+
+	if _, err := config.Words.scripts(); err != nil {
+		fail("words.scripts: %v", err)
+	}
+	if _, err := config.Words.language(); err != nil {
+		fail("words.language: %v", err)
+	}
+	for _, n := range config.Words.Lengths {
+		if n < 1 {
+			fail("words.lengths must be positive, got %d", n)
+		}
+	}
+
+	engine := &config.Engine
+	if engine.MaxConcurrentUsers == 0 {
+		engine.MaxConcurrentUsers = defaultConcurrentUsers
+	}
+	if engine.SolveTimeout == 0 {
+		engine.SolveTimeout = defaultSolveTimeout
+	}
+	if engine.CoachTimeout == 0 {
+		engine.CoachTimeout = defaultCoachTimeout
+	}
+	errs = append(errs, engine.check("engine")...)
+	// Engines are told apart by name in /status, /admin/workers and reloads
+	names := map[string]bool{}
+	for _, c := range config.engines() {
+		if c.Builtin || len(c.Backends) > 0 {
+			continue
+		}
+		if name := c.displayName(); names[name] {
+			fail("two engines are named %q; set name on one of them", name)
+		} else {
+			names[name] = true
+		}
+	}
+	if engine.AnswersPath != "" && engine.WordlistPath == "" {
+		fail("engine.answers_path needs engine.wordlist_path")
+	}
+
+	// Dictionaries only change the index that local engines are run with
+	local := (engine.ExecPath != "" || engine.WasmPath != "") && engine.GrpcAddr == "" && !engine.Builtin && len(engine.Backends) == 0
+	if len(config.Dictionaries) > 0 && !local {
+		fail("dictionaries need a local engine, with exec_path or wasm_path and without grpc_addr, builtin or backends")
+	}
+	for name, d := range config.Dictionaries {
+		if d.IndexPath == "" {
+			fail("dictionaries.%s.index_path is required", name)
+		}
+		if d.AnswersPath != "" && d.WordlistPath == "" {
+			fail("dictionaries.%s.answers_path needs wordlist_path", name)
+		}
+		for _, n := range d.Lengths {
+			if n < 1 {
+				fail("dictionaries.%s.lengths must be positive, got %d", name, n)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// check validates an engine config and those of its backends, canary and
+// shadow. path is the TOML key of the engine's table
+func (config BotConfig) check(path string) (errs []error) {
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(path+"."+format, args...))
+	}
+
+	local := config.ExecPath != "" || config.WasmPath != ""
+	switch {
+	case !local && config.GrpcAddr == "" && !config.Builtin && len(config.Backends) == 0:
+		fail("exec_path is required, unless the engine is set up with wasm_path, grpc_addr, builtin or backends")
+	case local && config.GrpcAddr == "" && !config.Builtin && config.IndexPath == "":
+		fail("index_path is required with exec_path or wasm_path")
+	}
+
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"max_concurrent_users", config.MaxConcurrentUsers},
+		{"solve_workers", config.SolveWorkers},
+		{"coach_workers", config.CoachWorkers},
+		{"admin_workers", config.AdminWorkers},
+		{"max_sessions", config.MaxSessions},
+		{"max_queue", config.MaxQueue},
+		{"prewarm", config.Prewarm},
+		{"solve_shards", config.SolveShards},
+		{"solve_timeout", config.SolveTimeout},
+		{"coach_timeout", config.CoachTimeout},
+		{"queue_timeout", config.QueueTimeout},
+		{"stall_timeout", config.StallTimeout},
+		{"breaker_threshold", config.BreakerThreshold},
+		{"breaker_cooldown", config.BreakerCooldown},
+		{"slow_threshold", config.SlowThreshold},
+		{"max_failures", config.MaxFailures},
+		{"health_interval", config.HealthInterval},
+		{"retry.max_attempts", config.Retry.MaxAttempts},
+		{"retry.backoff", config.Retry.Backoff},
+	} {
+		if setting.value < 0 {
+			fail("%s must not be negative, got %d", setting.key, setting.value)
+		}
+	}
+
+	if !slices.Contains([]string{"", "exit", "decode", "any"}, config.Retry.On) {
+		fail("retry.on must be exit, decode or any, got %q", config.Retry.On)
+	}
+	if config.CanaryPercent < 0 || config.CanaryPercent > 100 {
+		fail("canary_percent must be between 0 and 100, got %v", config.CanaryPercent)
+	}
+	for op, n := range config.MaxOutput {
+		if n <= 0 {
+			fail("max_output.%s must be positive, got %d", op, n)
+		}
+	}
+
+	// Settings left out of these are inherited, and were checked above
+	for i, b := range config.Backends {
+		errs = append(errs, b.check(fmt.Sprintf("%s.backends[%d]", path, i))...)
+	}
+	if config.Canary != nil {
+		errs = append(errs, config.Canary.check(path+".canary")...)
+	}
+	if config.Shadow != nil {
+		errs = append(errs, config.Shadow.check(path+".shadow")...)
+	}
+	return
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"runtime/debug"
+)
+
+// version can be set with -ldflags "-X main.version=v1.2.3"; otherwise it's
+// taken from the module version or VCS revision the binary was built from
+var version string
+
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+type wasmModule struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	indexDir string
+	index    string
+}
+
+// cappedBuffer keeps the first max bytes written to it, and counts the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	max  int64
+	size int64
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.size += int64(len(p))
+	if c.size > c.max {
+		return len(p), nil
+	}
+	return c.Buffer.Write(p)
+}
+
+func newWasmModule(config BotConfig) (*wasmModule, error) {
+	bin, err := os.ReadFile(config.WasmPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	rtConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+
+	compiled, err := runtime.CompileModule(ctx, bin)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	return &wasmModule{
+		runtime:  runtime,
+		compiled: compiled,
+		indexDir: filepath.Dir(config.IndexPath),
+		index:    filepath.Join("/index", filepath.Base(config.IndexPath)),
+	}, nil
+}
+
+func (w *wasmModule) Close() {
+	w.runtime.Close(context.Background())
+}
+
+func (w *wasmModule) run(ctx context.Context, res *execResult, opener *string, maxOutput int64, v any, args ...string) error {
+	stdout := cappedBuffer{max: maxOutput}
+
+	fsConfig := wazero.NewFSConfig().WithReadOnlyDirMount(w.indexDir, "/index")
+	modConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{"wordsmith"}, args...)...).
+		WithEnv("WORDSMITH_INDEX", w.index).
+		WithStdout(&stdout).
+		WithFSConfig(fsConfig)
+	if opener != nil {
+		modConfig = modConfig.WithEnv("WORDSMITH_OPENER", *opener)
+	}
+
+	mod, err := w.runtime.InstantiateModule(ctx, w.compiled, modConfig)
+	if mod != nil {
+		mod.Close(ctx)
+	}
+	res.output = stdout.size
+	var exitErr *sys.ExitError
+	if err == nil {
+		res.exitCode = 0
+	} else if errors.As(err, &exitErr) {
+		res.exitCode = int(exitErr.ExitCode())
+	}
+
+	if ctxErr := ctx.Err(); errors.Is(ctxErr, context.Canceled) {
+		return ctxErr
+	} else if ctxErr != nil {
+		return TimeoutError("timeout")
+	}
+	if exitErr != nil {
+		return exitError(err, res.exitCode)
+	} else if err != nil {
+		return err
+	}
+
+	if stdout.size > stdout.max {
+		return TruncatedError{Size: stdout.size, Limit: stdout.max}
+	}
+
+	if err := decodeInto(json.NewDecoder(&stdout), v); err != nil {
+		var streamErr StreamError
+		if errors.As(err, &streamErr) {
+			return streamErr
+		}
+		return DecodeError{err}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay lets a file settle before it's reloaded, as writing one tends to
+// cause a burst of events
+const watchDelay = 500 * time.Millisecond
+
+// fileWatch runs an action once the files it was added for stop changing.
+// Directories are watched rather than the files themselves, so files that
+// are replaced by renaming another over them are noticed
+type fileWatch struct {
+	watcher *fsnotify.Watcher
+	files   map[string]string
+	dirs    map[string]string
+	actions map[string]func()
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// watchFiles reloads the config when it or a file in conf.d changes, and the
+// word lists when they're read from files, and has engines pick up a replaced
+// executable or index
+func watchFiles(config *ConfigFile) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("Not watching files for changes", "err", err)
+		return
+	}
+
+	fw := &fileWatch{
+		watcher: watcher,
+		files:   make(map[string]string),
+		dirs:    make(map[string]string),
+		actions: make(map[string]func()),
+		timers:  make(map[string]*time.Timer),
+	}
+	fw.actions["config"] = func() {
+		slog.Info("Config changed, reloading")
+		logReload(reloadConfig())
+	}
+	fw.addFile(globalConfigPath, "config")
+	fw.addDir(includeDir(), "config")
+
+	fw.actions["words"] = func() {
+		slog.Info("Word list changed, reloading")
+		if _, err := refreshDictionary(); err != nil {
+			slog.Error("Failed to reload words", "err", err)
+		}
+	}
+	fw.addFile(config.Engine.WordlistPath, "words")
+	fw.addFile(config.Engine.AnswersPath, "words")
+	for _, d := range config.Dictionaries {
+		fw.addFile(d.WordlistPath, "words")
+		fw.addFile(d.AnswersPath, "words")
+	}
+
+	botsMu.Lock()
+	for _, c := range config.engines() {
+		b, ok := bots[c.displayName()]
+		if !ok {
+			continue
+		}
+
+		name := "engine " + c.displayName()
+		fw.actions[name] = b.reloadFiles
+		fw.addFile(c.ExecPath, name)
+		fw.addFile(c.IndexPath, name)
+		if c.WasmPath != "" {
+			fw.actions[name+" module"] = func() {
+				slog.Warn("Engine module changed, restart to load it", "engine", c.displayName(), "path", c.WasmPath)
+			}
+			fw.addFile(c.WasmPath, name+" module")
+		}
+	}
+	botsMu.Unlock()
+
+	go fw.run()
+}
+
+func (fw *fileWatch) addFile(path, action string) {
+	if path == "" {
+		return
+	}
+	path = absPath(path)
+	fw.files[path] = action
+	if err := fw.watcher.Add(filepath.Dir(path)); err != nil {
+		slog.Warn("Not watching file for changes", "path", path, "err", err)
+	}
+}
+
+func (fw *fileWatch) addDir(dir, action string) {
+	dir = absPath(dir)
+	if err := fw.watcher.Add(dir); err == nil {
+		fw.dirs[dir] = action
+	}
+}
+
+// absPath makes path absolute, as the watcher names files by the path their
+// directory was first added with, which may be relative
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func (fw *fileWatch) run() {
+	for {
+		select {
+		case event, ok := <-fw.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			for _, action := range fw.eventActions(absPath(event.Name)) {
+				fw.schedule(action)
+			}
+		case err, ok := <-fw.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("Error watching files", "err", err)
+		}
+	}
+}
+
+// eventActions lists the actions for a change to path
+func (fw *fileWatch) eventActions(path string) []string {
+	if action, ok := fw.files[path]; ok {
+		return []string{action}
+	}
+	dir := filepath.Dir(path)
+	if action, ok := fw.dirs[dir]; ok {
+		return []string{action}
+	}
+
+	// Kubernetes updates mounted ConfigMaps and Secrets by swapping a ..data
+	// symlink, so every file in the directory may have changed
+	var actions []string
+	if strings.HasPrefix(filepath.Base(path), "..") {
+		for file, action := range fw.files {
+			if filepath.Dir(file) == dir {
+				actions = append(actions, action)
+			}
+		}
+	}
+	return actions
+}
+
+func (fw *fileWatch) schedule(action string) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if t, ok := fw.timers[action]; ok {
+		t.Reset(watchDelay)
+		return
+	}
+	fw.timers[action] = time.AfterFunc(watchDelay, func() {
+		fw.mu.Lock()
+		delete(fw.timers, action)
+		fw.mu.Unlock()
+		fw.actions[action]()
+	})
+}
+
+// reloadFiles has the engine pick up a replaced executable or index.
+// Prewarmed processes started with the old files are replaced, the opener is
+// recomputed, results cached or precomputed from the old files are no longer
+// served and the word lists are reloaded
+func (b *Bot) reloadFiles() {
+	name := b.config.displayName()
+	old := b.files.Load()
+	files := b.hashFiles()
+	if old != nil && *old == *files {
+		return
+	}
+
+	var changed []string
+	if old == nil || old.exec != files.exec {
+		changed = append(changed, b.config.ExecPath)
+	}
+	if old == nil || old.index != files.index {
+		changed = append(changed, b.config.IndexPath)
+	}
+	slog.Info("Engine files changed", "engine", name, "files", changed, "version", files.version)
+	b.replaced.Store(true)
+
+	if b.wasm == nil {
+		if err := b.config.validateExec(); err != nil {
+			slog.Error("Replaced engine executable is unusable", "engine", name, "err", err)
+			reason := err.Error()
+			b.unusable.Store(&reason)
+		} else {
+			b.unusable.Store(nil)
+		}
+	}
+
+	for range b.drainWarm() {
+		go b.spawnWarm()
+	}
+	b.opener.Store(nil)
+	b.loadOpener()
+
+	if _, err := refreshDictionary(); err != nil {
+		slog.Error("Failed to reload words", "err", err)
+	}
+}
@@ -0,0 +1,765 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: wbot.proto
+
+package wbotpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Guess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Word          string                 `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	Score         float32                `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Guess) Reset() {
+	*x = Guess{}
+	mi := &file_wbot_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Guess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Guess) ProtoMessage() {}
+
+func (x *Guess) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Guess.ProtoReflect.Descriptor instead.
+func (*Guess) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Guess) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+func (x *Guess) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type WordReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *Guess                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Best          []*Guess               `protobuf:"bytes,2,rep,name=best,proto3" json:"best,omitempty"`
+	OptionsLeft   []string               `protobuf:"bytes,3,rep,name=options_left,json=optionsLeft,proto3" json:"options_left,omitempty"`
+	Eliminated    int32                  `protobuf:"varint,4,opt,name=eliminated,proto3" json:"eliminated,omitempty"`
+	Colors        string                 `protobuf:"bytes,5,opt,name=colors,proto3" json:"colors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordReport) Reset() {
+	*x = WordReport{}
+	mi := &file_wbot_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordReport) ProtoMessage() {}
+
+func (x *WordReport) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordReport.ProtoReflect.Descriptor instead.
+func (*WordReport) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WordReport) GetUser() *Guess {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *WordReport) GetBest() []*Guess {
+	if x != nil {
+		return x.Best
+	}
+	return nil
+}
+
+func (x *WordReport) GetOptionsLeft() []string {
+	if x != nil {
+		return x.OptionsLeft
+	}
+	return nil
+}
+
+func (x *WordReport) GetEliminated() int32 {
+	if x != nil {
+		return x.Eliminated
+	}
+	return 0
+}
+
+func (x *WordReport) GetColors() string {
+	if x != nil {
+		return x.Colors
+	}
+	return ""
+}
+
+type SolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Word          string                 `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SolveRequest) Reset() {
+	*x = SolveRequest{}
+	mi := &file_wbot_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveRequest) ProtoMessage() {}
+
+func (x *SolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveRequest.ProtoReflect.Descriptor instead.
+func (*SolveRequest) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SolveRequest) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+type SolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reports       []*WordReport          `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SolveResponse) Reset() {
+	*x = SolveResponse{}
+	mi := &file_wbot_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolveResponse) ProtoMessage() {}
+
+func (x *SolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolveResponse.ProtoReflect.Descriptor instead.
+func (*SolveResponse) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SolveResponse) GetReports() []*WordReport {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type CoachRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Word          string                 `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	Guesses       []string               `protobuf:"bytes,2,rep,name=guesses,proto3" json:"guesses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoachRequest) Reset() {
+	*x = CoachRequest{}
+	mi := &file_wbot_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoachRequest) ProtoMessage() {}
+
+func (x *CoachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoachRequest.ProtoReflect.Descriptor instead.
+func (*CoachRequest) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CoachRequest) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+func (x *CoachRequest) GetGuesses() []string {
+	if x != nil {
+		return x.Guesses
+	}
+	return nil
+}
+
+type Feedback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Word          string                 `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	Colors        string                 `protobuf:"bytes,2,opt,name=colors,proto3" json:"colors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
+	mi := &file_wbot_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feedback) ProtoMessage() {}
+
+func (x *Feedback) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Feedback) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+func (x *Feedback) GetColors() string {
+	if x != nil {
+		return x.Colors
+	}
+	return ""
+}
+
+type CoachFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feedback      []*Feedback            `protobuf:"bytes,1,rep,name=feedback,proto3" json:"feedback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoachFeedbackRequest) Reset() {
+	*x = CoachFeedbackRequest{}
+	mi := &file_wbot_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoachFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoachFeedbackRequest) ProtoMessage() {}
+
+func (x *CoachFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoachFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*CoachFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CoachFeedbackRequest) GetFeedback() []*Feedback {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+type YellowLetter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Letter        string                 `protobuf:"bytes,1,opt,name=letter,proto3" json:"letter,omitempty"`
+	Position      int32                  `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *YellowLetter) Reset() {
+	*x = YellowLetter{}
+	mi := &file_wbot_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *YellowLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*YellowLetter) ProtoMessage() {}
+
+func (x *YellowLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use YellowLetter.ProtoReflect.Descriptor instead.
+func (*YellowLetter) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *YellowLetter) GetLetter() string {
+	if x != nil {
+		return x.Letter
+	}
+	return ""
+}
+
+func (x *YellowLetter) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+type SuggestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Green         string                 `protobuf:"bytes,1,opt,name=green,proto3" json:"green,omitempty"`
+	Yellow        []*YellowLetter        `protobuf:"bytes,2,rep,name=yellow,proto3" json:"yellow,omitempty"`
+	Gray          string                 `protobuf:"bytes,3,opt,name=gray,proto3" json:"gray,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestRequest) Reset() {
+	*x = SuggestRequest{}
+	mi := &file_wbot_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestRequest) ProtoMessage() {}
+
+func (x *SuggestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestRequest.ProtoReflect.Descriptor instead.
+func (*SuggestRequest) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SuggestRequest) GetGreen() string {
+	if x != nil {
+		return x.Green
+	}
+	return ""
+}
+
+func (x *SuggestRequest) GetYellow() []*YellowLetter {
+	if x != nil {
+		return x.Yellow
+	}
+	return nil
+}
+
+func (x *SuggestRequest) GetGray() string {
+	if x != nil {
+		return x.Gray
+	}
+	return ""
+}
+
+type Suggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Best          []*Guess               `protobuf:"bytes,1,rep,name=best,proto3" json:"best,omitempty"`
+	OptionsLeft   []string               `protobuf:"bytes,2,rep,name=options_left,json=optionsLeft,proto3" json:"options_left,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_wbot_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Suggestion) GetBest() []*Guess {
+	if x != nil {
+		return x.Best
+	}
+	return nil
+}
+
+func (x *Suggestion) GetOptionsLeft() []string {
+	if x != nil {
+		return x.OptionsLeft
+	}
+	return nil
+}
+
+type WordListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordListRequest) Reset() {
+	*x = WordListRequest{}
+	mi := &file_wbot_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordListRequest) ProtoMessage() {}
+
+func (x *WordListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordListRequest.ProtoReflect.Descriptor instead.
+func (*WordListRequest) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WordListRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+type WordListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordListResponse) Reset() {
+	*x = WordListResponse{}
+	mi := &file_wbot_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordListResponse) ProtoMessage() {}
+
+func (x *WordListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wbot_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordListResponse.ProtoReflect.Descriptor instead.
+func (*WordListResponse) Descriptor() ([]byte, []int) {
+	return file_wbot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WordListResponse) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+var File_wbot_proto protoreflect.FileDescriptor
+
+const file_wbot_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"wbot.proto\x12\x04wbot\"1\n" +
+	"\x05Guess\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x02R\x05score\"\xa9\x01\n" +
+	"\n" +
+	"WordReport\x12\x1f\n" +
+	"\x04user\x18\x01 \x01(\v2\v.wbot.GuessR\x04user\x12\x1f\n" +
+	"\x04best\x18\x02 \x03(\v2\v.wbot.GuessR\x04best\x12!\n" +
+	"\foptions_left\x18\x03 \x03(\tR\voptionsLeft\x12\x1e\n" +
+	"\n" +
+	"eliminated\x18\x04 \x01(\x05R\n" +
+	"eliminated\x12\x16\n" +
+	"\x06colors\x18\x05 \x01(\tR\x06colors\"\"\n" +
+	"\fSolveRequest\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\";\n" +
+	"\rSolveResponse\x12*\n" +
+	"\areports\x18\x01 \x03(\v2\x10.wbot.WordReportR\areports\"<\n" +
+	"\fCoachRequest\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\x12\x18\n" +
+	"\aguesses\x18\x02 \x03(\tR\aguesses\"6\n" +
+	"\bFeedback\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\x12\x16\n" +
+	"\x06colors\x18\x02 \x01(\tR\x06colors\"B\n" +
+	"\x14CoachFeedbackRequest\x12*\n" +
+	"\bfeedback\x18\x01 \x03(\v2\x0e.wbot.FeedbackR\bfeedback\"B\n" +
+	"\fYellowLetter\x12\x16\n" +
+	"\x06letter\x18\x01 \x01(\tR\x06letter\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x05R\bposition\"f\n" +
+	"\x0eSuggestRequest\x12\x14\n" +
+	"\x05green\x18\x01 \x01(\tR\x05green\x12*\n" +
+	"\x06yellow\x18\x02 \x03(\v2\x12.wbot.YellowLetterR\x06yellow\x12\x12\n" +
+	"\x04gray\x18\x03 \x01(\tR\x04gray\"P\n" +
+	"\n" +
+	"Suggestion\x12\x1f\n" +
+	"\x04best\x18\x01 \x03(\v2\v.wbot.GuessR\x04best\x12!\n" +
+	"\foptions_left\x18\x02 \x03(\tR\voptionsLeft\"%\n" +
+	"\x0fWordListRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\"(\n" +
+	"\x10WordListResponse\x12\x14\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words2\x96\x02\n" +
+	"\x06Engine\x120\n" +
+	"\x05Solve\x12\x12.wbot.SolveRequest\x1a\x13.wbot.SolveResponse\x12-\n" +
+	"\x05Coach\x12\x12.wbot.CoachRequest\x1a\x10.wbot.WordReport\x12=\n" +
+	"\rCoachFeedback\x12\x1a.wbot.CoachFeedbackRequest\x1a\x10.wbot.WordReport\x121\n" +
+	"\aSuggest\x12\x14.wbot.SuggestRequest\x1a\x10.wbot.Suggestion\x129\n" +
+	"\bWordList\x12\x15.wbot.WordListRequest\x1a\x16.wbot.WordListResponseB(Z&github.com/antonijn/wbot-server/wbotpbb\x06proto3"
+
+var (
+	file_wbot_proto_rawDescOnce sync.Once
+	file_wbot_proto_rawDescData []byte
+)
+
+func file_wbot_proto_rawDescGZIP() []byte {
+	file_wbot_proto_rawDescOnce.Do(func() {
+		file_wbot_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wbot_proto_rawDesc), len(file_wbot_proto_rawDesc)))
+	})
+	return file_wbot_proto_rawDescData
+}
+
+var file_wbot_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_wbot_proto_goTypes = []any{
+	(*Guess)(nil),                // 0: wbot.Guess
+	(*WordReport)(nil),           // 1: wbot.WordReport
+	(*SolveRequest)(nil),         // 2: wbot.SolveRequest
+	(*SolveResponse)(nil),        // 3: wbot.SolveResponse
+	(*CoachRequest)(nil),         // 4: wbot.CoachRequest
+	(*Feedback)(nil),             // 5: wbot.Feedback
+	(*CoachFeedbackRequest)(nil), // 6: wbot.CoachFeedbackRequest
+	(*YellowLetter)(nil),         // 7: wbot.YellowLetter
+	(*SuggestRequest)(nil),       // 8: wbot.SuggestRequest
+	(*Suggestion)(nil),           // 9: wbot.Suggestion
+	(*WordListRequest)(nil),      // 10: wbot.WordListRequest
+	(*WordListResponse)(nil),     // 11: wbot.WordListResponse
+}
+var file_wbot_proto_depIdxs = []int32{
+	0,  // 0: wbot.WordReport.user:type_name -> wbot.Guess
+	0,  // 1: wbot.WordReport.best:type_name -> wbot.Guess
+	1,  // 2: wbot.SolveResponse.reports:type_name -> wbot.WordReport
+	5,  // 3: wbot.CoachFeedbackRequest.feedback:type_name -> wbot.Feedback
+	7,  // 4: wbot.SuggestRequest.yellow:type_name -> wbot.YellowLetter
+	0,  // 5: wbot.Suggestion.best:type_name -> wbot.Guess
+	2,  // 6: wbot.Engine.Solve:input_type -> wbot.SolveRequest
+	4,  // 7: wbot.Engine.Coach:input_type -> wbot.CoachRequest
+	6,  // 8: wbot.Engine.CoachFeedback:input_type -> wbot.CoachFeedbackRequest
+	8,  // 9: wbot.Engine.Suggest:input_type -> wbot.SuggestRequest
+	10, // 10: wbot.Engine.WordList:input_type -> wbot.WordListRequest
+	3,  // 11: wbot.Engine.Solve:output_type -> wbot.SolveResponse
+	1,  // 12: wbot.Engine.Coach:output_type -> wbot.WordReport
+	1,  // 13: wbot.Engine.CoachFeedback:output_type -> wbot.WordReport
+	9,  // 14: wbot.Engine.Suggest:output_type -> wbot.Suggestion
+	11, // 15: wbot.Engine.WordList:output_type -> wbot.WordListResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_wbot_proto_init() }
+func file_wbot_proto_init() {
+	if File_wbot_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wbot_proto_rawDesc), len(file_wbot_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_wbot_proto_goTypes,
+		DependencyIndexes: file_wbot_proto_depIdxs,
+		MessageInfos:      file_wbot_proto_msgTypes,
+	}.Build()
+	File_wbot_proto = out.File
+	file_wbot_proto_goTypes = nil
+	file_wbot_proto_depIdxs = nil
+}
@@ -0,0 +1,273 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.29.3
+// source: wbot.proto
+
+package wbotpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Engine_Solve_FullMethodName         = "/wbot.Engine/Solve"
+	Engine_Coach_FullMethodName         = "/wbot.Engine/Coach"
+	Engine_CoachFeedback_FullMethodName = "/wbot.Engine/CoachFeedback"
+	Engine_Suggest_FullMethodName       = "/wbot.Engine/Suggest"
+	Engine_WordList_FullMethodName      = "/wbot.Engine/WordList"
+)
+
+// EngineClient is the client API for Engine service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EngineClient interface {
+	Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error)
+	Coach(ctx context.Context, in *CoachRequest, opts ...grpc.CallOption) (*WordReport, error)
+	CoachFeedback(ctx context.Context, in *CoachFeedbackRequest, opts ...grpc.CallOption) (*WordReport, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*Suggestion, error)
+	WordList(ctx context.Context, in *WordListRequest, opts ...grpc.CallOption) (*WordListResponse, error)
+}
+
+type engineClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEngineClient(cc grpc.ClientConnInterface) EngineClient {
+	return &engineClient{cc}
+}
+
+func (c *engineClient) Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SolveResponse)
+	err := c.cc.Invoke(ctx, Engine_Solve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) Coach(ctx context.Context, in *CoachRequest, opts ...grpc.CallOption) (*WordReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WordReport)
+	err := c.cc.Invoke(ctx, Engine_Coach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) CoachFeedback(ctx context.Context, in *CoachFeedbackRequest, opts ...grpc.CallOption) (*WordReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WordReport)
+	err := c.cc.Invoke(ctx, Engine_CoachFeedback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*Suggestion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Suggestion)
+	err := c.cc.Invoke(ctx, Engine_Suggest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) WordList(ctx context.Context, in *WordListRequest, opts ...grpc.CallOption) (*WordListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WordListResponse)
+	err := c.cc.Invoke(ctx, Engine_WordList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EngineServer is the server API for Engine service.
+// All implementations must embed UnimplementedEngineServer
+// for forward compatibility.
+type EngineServer interface {
+	Solve(context.Context, *SolveRequest) (*SolveResponse, error)
+	Coach(context.Context, *CoachRequest) (*WordReport, error)
+	CoachFeedback(context.Context, *CoachFeedbackRequest) (*WordReport, error)
+	Suggest(context.Context, *SuggestRequest) (*Suggestion, error)
+	WordList(context.Context, *WordListRequest) (*WordListResponse, error)
+	mustEmbedUnimplementedEngineServer()
+}
+
+// UnimplementedEngineServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEngineServer struct{}
+
+func (UnimplementedEngineServer) Solve(context.Context, *SolveRequest) (*SolveResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Solve not implemented")
+}
+func (UnimplementedEngineServer) Coach(context.Context, *CoachRequest) (*WordReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method Coach not implemented")
+}
+func (UnimplementedEngineServer) CoachFeedback(context.Context, *CoachFeedbackRequest) (*WordReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method CoachFeedback not implemented")
+}
+func (UnimplementedEngineServer) Suggest(context.Context, *SuggestRequest) (*Suggestion, error) {
+	return nil, status.Error(codes.Unimplemented, "method Suggest not implemented")
+}
+func (UnimplementedEngineServer) WordList(context.Context, *WordListRequest) (*WordListResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WordList not implemented")
+}
+func (UnimplementedEngineServer) mustEmbedUnimplementedEngineServer() {}
+func (UnimplementedEngineServer) testEmbeddedByValue()                {}
+
+// UnsafeEngineServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EngineServer will
+// result in compilation errors.
+type UnsafeEngineServer interface {
+	mustEmbedUnimplementedEngineServer()
+}
+
+func RegisterEngineServer(s grpc.ServiceRegistrar, srv EngineServer) {
+	// If the following call panics, it indicates UnimplementedEngineServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Engine_ServiceDesc, srv)
+}
+
+func _Engine_Solve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Solve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Solve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Solve(ctx, req.(*SolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_Coach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoachRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Coach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Coach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Coach(ctx, req.(*CoachRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_CoachFeedback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoachFeedbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).CoachFeedback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_CoachFeedback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).CoachFeedback(ctx, req.(*CoachFeedbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Suggest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_WordList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).WordList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_WordList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).WordList(ctx, req.(*WordListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Engine_ServiceDesc is the grpc.ServiceDesc for Engine service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Engine_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wbot.Engine",
+	HandlerType: (*EngineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Solve",
+			Handler:    _Engine_Solve_Handler,
+		},
+		{
+			MethodName: "Coach",
+			Handler:    _Engine_Coach_Handler,
+		},
+		{
+			MethodName: "CoachFeedback",
+			Handler:    _Engine_CoachFeedback_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _Engine_Suggest_Handler,
+		},
+		{
+			MethodName: "WordList",
+			Handler:    _Engine_WordList_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "wbot.proto",
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// readWordList reads a word list from a file, which is either a JSON array
+// like the engine's output or has a word per line. Blank lines and lines
+// starting with # are skipped. Words must have one of lengths
+func readWordList(path string, lengths []int) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &words); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			word := strings.TrimSpace(scanner.Text())
+			if word != "" && !strings.HasPrefix(word, "#") {
+				words = append(words, word)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s: no words", path)
+	}
+	for i, word := range words {
+		words[i] = foldWord(word)
+		if !wordValid(words[i]) || !slices.Contains(lengths, utf8.RuneCountInString(words[i])) {
+			return nil, fmt.Errorf("%s: invalid word %q", path, word)
+		}
+	}
+	return words, nil
+}
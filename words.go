@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+type WordsConfig struct {
+	Scripts  []string `toml:"scripts" comment:"Unicode scripts that the letters of words may be from, such as \"Latin\",\n\"Greek\" or \"Cyrillic\"; Latin only if unset"`
+	Lengths  []int    `toml:"lengths" comment:"Numbers of letters that words of the engine's own dictionary may have, the\nfirst being the default for len=; 5 only if unset"`
+	Language string   `toml:"language" comment:"BCP 47 tag of the language whose rules lowercase words, such as \"tr\" for\nthe Turkish dotted and dotless i; rules common to all languages if unset"`
+}
+
+// wordScripts are the scripts that the letters of words may be from, and
+// wordLanguage the language whose rules lowercase them. wordLengths are the
+// lengths of words in the engine's own dictionary and dictionaryLengths those
+// in [dictionaries] that set their own. validLengths has the lengths of every
+// dictionary. They are set once at startup, before any engine is set up
+var (
+	wordScripts       = []*unicode.RangeTable{unicode.Latin}
+	wordLanguage      = language.Und
+	wordLengths       = []int{5}
+	dictionaryLengths = map[string][]int{}
+	validLengths      = map[int]bool{5: true}
+)
+
+// setupWords sets what words may consist of from config
+func setupWords(config *ConfigFile) {
+	wordScripts, _ = config.Words.scripts()
+	wordLanguage, _ = config.Words.language()
+	wordLengths = config.Words.lengths()
+	validLengths = map[int]bool{}
+	for _, n := range wordLengths {
+		validLengths[n] = true
+	}
+	for name, d := range config.Dictionaries {
+		if len(d.Lengths) > 0 {
+			dictionaryLengths[name] = d.Lengths
+		}
+		for _, n := range d.Lengths {
+			validLengths[n] = true
+		}
+	}
+}
+
+func (config WordsConfig) lengths() []int {
+	if len(config.Lengths) == 0 {
+		return []int{5}
+	}
+	return config.Lengths
+}
+
+func (config WordsConfig) language() (language.Tag, error) {
+	if config.Language == "" {
+		return language.Und, nil
+	}
+	return language.Parse(config.Language)
+}
+
+func (config WordsConfig) scripts() ([]*unicode.RangeTable, error) {
+	if len(config.Scripts) == 0 {
+		return []*unicode.RangeTable{unicode.Latin}, nil
+	}
+	tables := make([]*unicode.RangeTable, len(config.Scripts))
+	for i, name := range config.Scripts {
+		table, ok := unicode.Scripts[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown Unicode script %q", name)
+		}
+		tables[i] = table
+	}
+	return tables, nil
+}
+
+// foldWord lowercases a word by the rules of wordLanguage and normalizes it to
+// NFC, so that an accented letter is a single rune however the client
+// composed it. Words are passed to the engine in this form, and compared with
+// the word lists in it. A Caser keeps state, so each call gets its own
+func foldWord(word string) string {
+	return norm.NFC.String(cases.Lower(wordLanguage).String(word))
+}
+
+// letterValid reports whether r is a letter of one of wordScripts
+func letterValid(r rune) bool {
+	return unicode.IsLetter(r) && unicode.IsOneOf(wordScripts, r)
+}
+
+// lengthsFor lists the lengths of words in the dictionary name, the first
+// being the default
+func lengthsFor(name string) []int {
+	if lengths, ok := dictionaryLengths[name]; ok {
+		return lengths
+	}
+	return wordLengths
+}
+
+type lengthKey struct{}
+
+func withLength(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, lengthKey{}, n)
+}
+
+// lengthFrom is the word length chosen for a request with len=, or 0
+func lengthFrom(ctx context.Context) int {
+	n, _ := ctx.Value(lengthKey{}).(int)
+	return n
+}
+
+// wordLength is the word length chosen for a request, or the default of its
+// dictionary
+func wordLength(ctx context.Context) int {
+	if n := lengthFrom(ctx); n != 0 {
+		return n
+	}
+	return lengthsFor(dictionaryFrom(ctx))[0]
+}
+
+// chooseLength has the request use the word length of its len parameter, if
+// any, which its dictionary must support. It goes inside chooseDictionary
+func chooseLength(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := r.URL.Query().Get("len")
+		if s == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || !slices.Contains(lengthsFor(dictionaryFrom(r.Context())), n) {
+			httpError(w, "Unsupported word length", http.StatusBadRequest)
+			slog.InfoContext(r.Context(), "Invalid request", "ip", getIP(r), "param", "len", "len", s)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(withLength(r.Context(), n)))
+	})
+}
+
+// wordValidFor reports whether word is valid for a request: it must also
+// have as many letters as len= chose, or otherwise one of the lengths of the
+// request's dictionary
+func wordValidFor(ctx context.Context, word string) bool {
+	if !wordValid(word) {
+		return false
+	}
+	n := utf8.RuneCountInString(norm.NFC.String(word))
+	if chosen := lengthFrom(ctx); chosen != 0 {
+		return n == chosen
+	}
+	return slices.Contains(lengthsFor(dictionaryFrom(ctx)), n)
+}
+
+// sameLength reports whether words all have as many letters, as the target
+// and guesses of a game must
+func sameLength(words ...string) bool {
+	for _, w := range words {
+		if utf8.RuneCountInString(norm.NFC.String(w)) != utf8.RuneCountInString(norm.NFC.String(words[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+// ofLength filters words to those of n letters
+func ofLength(words []string, n int) []string {
+	var matches []string
+	for _, w := range words {
+		if utf8.RuneCountInString(w) == n {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
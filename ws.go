@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+type coachMessage struct {
+	Guess  string `json:"guess"`
+	Colors string `json:"colors"`
+}
+
+type socketError struct {
+	Error string `json:"error"`
+}
+
+func coachSocket(w http.ResponseWriter, r *http.Request) {
+	id := requestIDFrom(r.Context())
+	ip := getIP(r)
+
+	r.ParseForm()
+	word := r.Form.Get("w")
+
+	if word != "" && !wordValidFor(r.Context(), word) {
+		httpError(w, "Invalid target word", http.StatusBadRequest)
+		slog.InfoContext(r.Context(), "Invalid request", "endpoint", "/ws/coach", "ip", ip, "param", "w")
+		return
+	}
+
+	name, eng := router.pick(r.Context())
+	w.Header().Set("X-Wbot-Engine", name)
+
+	session, err := eng.CoachSession(requestContext(r), word)
+	if err != nil {
+		router.record(name, err)
+		internalError(w, r, err)
+		return
+	}
+	defer session.Close()
+
+	keepOpen(w)
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Websocket error", "err", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	slog.InfoContext(r.Context(), "Request", "endpoint", "/ws/coach", "ip", ip, "word", word, "engine", name)
+	start := time.Now()
+
+	ctx := r.Context()
+	for {
+		var msg coachMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			break
+		}
+
+		// With a target the engine scores guesses itself, so colors would
+		// only be passed to it unchecked
+		guess, colors := foldWord(msg.Guess), strings.ToLower(msg.Colors)
+		if !wordValidFor(r.Context(), guess) || (word != "" && (colors != "" || !sameLength(guess, word))) || (word == "" && !colorsValid(guess, colors)) {
+			wsjson.Write(ctx, conn, socketError{"Invalid guess"})
+			continue
+		}
+
+		report, err := session.Guess(Feedback{Word: guess, Colors: colors})
+		if requestFault(err) {
+			wsjson.Write(ctx, conn, socketError{"Invalid guess"})
+			continue
+		}
+		router.record(name, err)
+		if err != nil {
+			logFailure(r.Context(), err)
+			if reportable(err) {
+				reportError(r.Context(), r, err, nil)
+			}
+			wsjson.Write(ctx, conn, socketError{"Engine error: " + id})
+			conn.Close(websocket.StatusInternalError, "engine error")
+			break
+		}
+
+		if err := wsjson.Write(ctx, conn, report); err != nil {
+			break
+		}
+	}
+
+	slog.InfoContext(r.Context(), "Request done", "endpoint", "/ws/coach", "duration", time.Since(start))
+}